@@ -0,0 +1,187 @@
+package main
+
+import (
+    "archive/zip"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "time"
+)
+
+const (
+    defaultBootstrapURL = "https://www.python.org/ftp/python/3.11.9/python-3.11.9-embed-amd64.zip"
+    bootstrapTimeout    = 10 * time.Minute
+    embeddedPythonDir   = "python"
+)
+
+// bootstrapPython скачивает встраиваемый дистрибутив Python в python\
+// рядом с лаунчером. Архив сначала пишется во временный файл и
+// переименовывается только после проверки SHA-256, распаковка тоже идёт
+// во временный каталог, поэтому прерванная загрузка не оставляет
+// полуготовый python\.
+func bootstrapPython(baseDir, url, wantSHA256 string) error {
+    if runtime.GOOS != "windows" {
+        return errors.New("загрузка встраиваемого Python поддерживается только в Windows")
+    }
+    if url == "" {
+        url = defaultBootstrapURL
+    }
+    wantSHA256 = strings.ToLower(strings.TrimSpace(wantSHA256))
+    if wantSHA256 == "" {
+        return fmt.Errorf("не задана контрольная сумма bootstrap_python_sha256 в %s (значение SHA-256 публикуется на странице релиза python.org)", configFileName)
+    }
+
+    archive := filepath.Join(baseDir, "python-embed.zip")
+    if err := downloadVerified(url, archive, wantSHA256, os.Stderr); err != nil {
+        return err
+    }
+    defer os.Remove(archive)
+
+    target := filepath.Join(baseDir, embeddedPythonDir)
+    staging := target + ".tmp"
+    os.RemoveAll(staging)
+    if err := extractZip(archive, staging); err != nil {
+        os.RemoveAll(staging)
+        return fmt.Errorf("распаковка %s: %w", archive, err)
+    }
+    if err := os.RemoveAll(target); err != nil {
+        os.RemoveAll(staging)
+        return err
+    }
+    if err := os.Rename(staging, target); err != nil {
+        os.RemoveAll(staging)
+        return err
+    }
+    logger.Printf("Python распакован в %s", target)
+    return nil
+}
+
+// downloadVerified скачивает url во временный файл рядом с dest и
+// переименовывает его в dest, только если SHA-256 совпала. Если progress
+// не nil, туда выводится ход загрузки.
+func downloadVerified(url, dest, wantSHA256 string, progress io.Writer) error {
+    logger.Printf("Загрузка %s", url)
+    client := &http.Client{Timeout: bootstrapTimeout}
+    resp, err := client.Get(url)
+    if err != nil {
+        return fmt.Errorf("загрузка %s: %w", url, err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("загрузка %s: %s", url, resp.Status)
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(dest), filepath.Base(dest)+".*.part")
+    if err != nil {
+        return err
+    }
+    tmpName := tmp.Name()
+    defer os.Remove(tmpName)
+
+    hash := sha256.New()
+    writers := []io.Writer{tmp, hash}
+    var meter *progressWriter
+    if progress != nil {
+        meter = &progressWriter{total: resp.ContentLength, out: progress}
+        writers = append(writers, meter)
+    }
+    _, err = io.Copy(io.MultiWriter(writers...), resp.Body)
+    if meter != nil {
+        meter.finish()
+    }
+    if cerr := tmp.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        return fmt.Errorf("загрузка %s: %w", url, err)
+    }
+
+    got := hex.EncodeToString(hash.Sum(nil))
+    if got != wantSHA256 {
+        return fmt.Errorf("контрольная сумма архива не совпадает: ожидалась %s, получена %s", wantSHA256, got)
+    }
+    return os.Rename(tmpName, dest)
+}
+
+func extractZip(archive, dest string) error {
+    r, err := zip.OpenReader(archive)
+    if err != nil {
+        return err
+    }
+    defer r.Close()
+
+    root := filepath.Clean(dest) + string(os.PathSeparator)
+    for _, f := range r.File {
+        path := filepath.Join(dest, f.Name)
+        if !strings.HasPrefix(path, root) {
+            return fmt.Errorf("недопустимый путь в архиве: %s", f.Name)
+        }
+        if f.FileInfo().IsDir() {
+            if err := os.MkdirAll(path, 0o755); err != nil {
+                return err
+            }
+            continue
+        }
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            return err
+        }
+        if err := extractZipFile(f, path); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func extractZipFile(f *zip.File, path string) error {
+    src, err := f.Open()
+    if err != nil {
+        return err
+    }
+    defer src.Close()
+    dst, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(dst, src); err != nil {
+        dst.Close()
+        return err
+    }
+    return dst.Close()
+}
+
+type progressWriter struct {
+    total   int64
+    written int64
+    shown   int64
+    out     io.Writer
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+    p.written += int64(len(b))
+    if p.written-p.shown >= 1<<20 || p.written == p.total {
+        p.shown = p.written
+        if p.total > 0 {
+            fmt.Fprintf(p.out, "\rЗагружено %.1f из %.1f МБ (%d%%)", mib(p.written), mib(p.total), p.written*100/p.total)
+        } else {
+            fmt.Fprintf(p.out, "\rЗагружено %.1f МБ", mib(p.written))
+        }
+    }
+    return len(b), nil
+}
+
+func (p *progressWriter) finish() {
+    if p.shown > 0 {
+        fmt.Fprintln(p.out)
+    }
+}
+
+func mib(n int64) float64 {
+    return float64(n) / (1 << 20)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+    "io"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+const (
+    botStdoutLogName = "bot-stdout.log"
+    botStderrLogName = "bot-stderr.log"
+    botLogMaxSize    = 10 << 20
+)
+
+// botOutput — приёмники stdout и stderr процесса бота. Файлы общие для
+// всех перезапусков в рамках одного запуска лаунчера.
+type botOutput struct {
+    stdout, stderr io.Writer
+    closers        []io.Closer
+}
+
+func consoleOutput(console io.Writer) *botOutput {
+    if console != nil {
+        return &botOutput{stdout: console, stderr: console}
+    }
+    return &botOutput{stdout: os.Stdout, stderr: os.Stderr}
+}
+
+// openBotOutput пишет вывод бота в logs\bot-stdout.log и logs\bot-stderr.log
+// с отметкой времени в начале каждой строки и, если у лаунчера есть
+// консоль, дублирует его туда (или в console, если он задан).
+func openBotOutput(baseDir string, console io.Writer) (*botOutput, error) {
+    dir := filepath.Join(baseDir, logDirName)
+    stdoutFile, err := openRotatingFile(filepath.Join(dir, botStdoutLogName), botLogMaxSize, logMaxBackups)
+    if err != nil {
+        return nil, err
+    }
+    stderrFile, err := openRotatingFile(filepath.Join(dir, botStderrLogName), botLogMaxSize, logMaxBackups)
+    if err != nil {
+        stdoutFile.Close()
+        return nil, err
+    }
+    out := &botOutput{
+        stdout:  &timestampWriter{w: stdoutFile, lineStart: true},
+        stderr:  &timestampWriter{w: stderrFile, lineStart: true},
+        closers: []io.Closer{stdoutFile, stderrFile},
+    }
+    if console != nil {
+        out.stdout = io.MultiWriter(out.stdout, console)
+        out.stderr = io.MultiWriter(out.stderr, console)
+        return out, nil
+    }
+    if hasConsole(os.Stdout) {
+        out.stdout = io.MultiWriter(out.stdout, ignoreErrors{os.Stdout})
+    }
+    if hasConsole(os.Stderr) {
+        out.stderr = io.MultiWriter(out.stderr, ignoreErrors{os.Stderr})
+    }
+    return out, nil
+}
+
+func (o *botOutput) Close() {
+    for _, c := range o.closers {
+        c.Close()
+    }
+}
+
+// hasConsole сообщает, можно ли писать в f: у процесса без консоли
+// (например, запущенного без окна) стандартные потоки недоступны.
+func hasConsole(f *os.File) bool {
+    if f == nil {
+        return false
+    }
+    _, err := f.Stat()
+    return err == nil
+}
+
+// ignoreErrors не даёт закрытой консоли остановить запись в файл через
+// io.MultiWriter, который прерывается на первой ошибке.
+type ignoreErrors struct {
+    w io.Writer
+}
+
+func (i ignoreErrors) Write(p []byte) (int, error) {
+    i.w.Write(p)
+    return len(p), nil
+}
+
+// timestampWriter добавляет время в начало каждой строки.
+type timestampWriter struct {
+    mu        sync.Mutex
+    w         io.Writer
+    lineStart bool
+}
+
+func (t *timestampWriter) Write(p []byte) (int, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    written := 0
+    for len(p) > 0 {
+        if t.lineStart {
+            if _, err := io.WriteString(t.w, time.Now().Format(logTimestampLayout)+" "); err != nil {
+                return written, err
+            }
+            t.lineStart = false
+        }
+        n := len(p)
+        for i, b := range p {
+            if b == '\n' {
+                n = i + 1
+                t.lineStart = true
+                break
+            }
+        }
+        if _, err := t.w.Write(p[:n]); err != nil {
+            return written, err
+        }
+        written += n
+        p = p[n:]
+    }
+    return written, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// runCheckDeps проверяет, установлены ли и импортируются ли пакеты из
+// requirements.txt (или из списка --packages) в интерпретаторе бота, и
+// печатает отчёт в w. В отличие от --install-deps ничего не устанавливает:
+// там, где pip без присмотра запрещён, список отсутствующих пакетов
+// передаётся ИТ-службе. Возвращает exitDepsFailed, если чего-то не хватает.
+func runCheckDeps(opts *options, w io.Writer) int {
+    exePath, err := os.Executable()
+    if err != nil {
+        logger.Printf("Не удалось определить путь к exe: %v", err)
+        return exitFailure
+    }
+    baseDir := filepath.Dir(exePath)
+    cfg, err := loadConfig(baseDir)
+    if err != nil {
+        logger.Printf("Предупреждение: конфигурация проигнорирована: %v", err)
+    }
+
+    var names []string
+    source := "--packages"
+    if opts.packages != "" {
+        for _, item := range strings.Split(opts.packages, ",") {
+            if name := requirementName(item); name != "" {
+                names = append(names, name)
+            }
+        }
+    } else {
+        source = filepath.Join(baseDir, requirementsFileName)
+        names, err = requirementNames(source)
+        if errors.Is(err, os.ErrNotExist) {
+            logger.Printf("Файл %s не найден; перечислите пакеты в --packages", source)
+            return exitUsage
+        }
+        if err != nil {
+            logger.Printf("Не удалось прочитать %s: %v", source, err)
+            return exitFailure
+        }
+    }
+    if len(names) == 0 {
+        fmt.Fprintf(w, "Пакеты для проверки не заданы (%s)\n", source)
+        return exitOK
+    }
+
+    refreshPath()
+    var pythonExe, venvDir string
+    if pinned, pinnedSource := pinnedPythonPath(baseDir, opts, cfg); pinned != "" {
+        if pythonExe, err = lookPinnedPython(pinned); err != nil {
+            logger.Printf("Интерпретатор Python из %s не может быть использован: %v", pinnedSource, err)
+            return exitNoInterpreter
+        }
+    } else if venvDir, pythonExe = findVenvPython(baseDir); pythonExe == "" {
+        pythonExe = findPython(defaultCandidates(baseDir))
+    }
+    if pythonExe == "" {
+        logger.Printf("Не удалось найти интерпретатор Python")
+        return exitNoInterpreter
+    }
+
+    fmt.Fprintf(w, "Пакеты: %s\nИнтерпретатор: %s\n\n", source, pythonExe)
+    r := &doctorReport{w: w}
+    results, err := checkPackages(pythonExe, baseDir, botEnvironment(baseDir, opts, cfg, venvDir), names)
+    var missing []string
+    for _, res := range results {
+        switch res.status {
+        case "ok":
+            r.ok("%s %s", res.name, res.detail)
+        case "missing":
+            r.fail("%s не установлен", res.name)
+            missing = append(missing, res.name)
+        default:
+            r.fail("%s установлен, но не импортируется: %s", res.name, res.detail)
+            missing = append(missing, res.name)
+        }
+    }
+    if err != nil {
+        r.fail("Проверка прервана: %v", err)
+    }
+    for _, name := range unchecked(names, results) {
+        r.fail("%s не проверен", name)
+        missing = append(missing, name)
+    }
+
+    if !r.failed {
+        fmt.Fprintf(w, "\nВсе пакеты на месте: %d\n", len(names))
+        return exitOK
+    }
+    fmt.Fprintf(w, "\nОтсутствуют или не импортируются (%d из %d): %s\n", len(missing), len(names), strings.Join(missing, " "))
+    return exitDepsFailed
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+// runCleanup удаляет файлы, которые лаунчер создаёт во время работы, чтобы
+// перед новым развёртыванием каталог был чистым. Пока очистка идёт,
+// лаунчер держит блокировку, поэтому второй экземпляр не запустится
+// посреди удаления. Журналы удаляются только с withLogs.
+func runCleanup(withLogs bool, w io.Writer) int {
+    exePath, err := os.Executable()
+    if err != nil {
+        logger.Printf("Не удалось определить путь к exe: %v", err)
+        return exitFailure
+    }
+    baseDir := filepath.Dir(exePath)
+
+    lock, err := acquireLock(baseDir)
+    if err != nil {
+        var held *lockHeldError
+        if errors.As(err, &held) {
+            logger.Event("lock_held").ExitCode(exitLockHeld).Printf("Очистка отменена: %v. Остановите его и повторите", err)
+            return exitLockHeld
+        }
+        logger.Printf("Не удалось взять блокировку %s: %v", filepath.Join(baseDir, lockFileName), err)
+        return exitFailure
+    }
+    defer lock.release()
+
+    pidPath := filepath.Join(baseDir, pidFileName)
+    if pid := runningBotPID(pidPath, baseDir); pid > 0 {
+        logger.Event("lock_held").ExitCode(exitLockHeld).Printf("Очистка отменена: процесс бота PID %d из %s ещё работает. Завершите его и повторите", pid, pidPath)
+        return exitLockHeld
+    }
+
+    cfg, err := loadConfig(baseDir)
+    if err != nil {
+        logger.Printf("Предупреждение: конфигурация проигнорирована: %v", err)
+    }
+    readyFile := defaultReadyFileName
+    if cfg.ReadyFile != "" {
+        readyFile = cfg.ReadyFile
+    }
+    // Одиночные файлы удаляются os.Remove: ready_file задаётся в
+    // конфигурации, и ошибка в нём не должна стоить целого каталога.
+    files := []string{
+        pidPath,
+        filepath.Join(baseDir, statusFileName),
+        resolvePath(baseDir, readyFile),
+        filepath.Join(baseDir, depsSentinelName),
+        filepath.Join(baseDir, takeoverFileName),
+    }
+    // Временные файлы writeFileAtomic, оставшиеся после аварийного завершения.
+    for _, name := range []string{pidFileName, statusFileName} {
+        tmp, _ := filepath.Glob(filepath.Join(baseDir, name+".*.tmp"))
+        files = append(files, tmp...)
+    }
+    // Из update удаляются только скачанные архивы и распакованное
+    // обновление: installed_version нужен, чтобы не ставить ту же версию
+    // повторно, а backup — для отката.
+    updateDir := filepath.Join(baseDir, updateDirName)
+    for _, pattern := range []string{"package-*.zip", "package-*.zip.*.part"} {
+        downloads, _ := filepath.Glob(filepath.Join(updateDir, pattern))
+        files = append(files, downloads...)
+    }
+    dirs := []string{
+        filepath.Join(updateDir, updateStagedDirName),
+        filepath.Join(updateDir, updateStagedDirName+".tmp"),
+    }
+    if withLogs {
+        dirs = append(dirs, filepath.Join(baseDir, logDirName))
+    }
+
+    code, removed := exitOK, 0
+    remove := func(path string, removeFunc func(string) error) {
+        if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+            return
+        }
+        if filepath.Clean(path) == filepath.Clean(baseDir) || !isInsideDir(baseDir, path) {
+            logger.Printf("Пропущен %s: путь вне каталога лаунчера %s", path, baseDir)
+            code = exitFailure
+            return
+        }
+        if err := removeFunc(path); err != nil {
+            logger.Printf("Не удалось удалить %s: %v", path, err)
+            code = exitFailure
+            return
+        }
+        fmt.Fprintf(w, "Удалено: %s\n", path)
+        removed++
+    }
+    for _, path := range files {
+        remove(path, os.Remove)
+    }
+    for _, path := range dirs {
+        remove(path, os.RemoveAll)
+    }
+    if removed == 0 && code == exitOK {
+        fmt.Fprintln(w, "Файлов лаунчера для удаления нет")
+    }
+    if !withLogs {
+        if _, err := os.Stat(filepath.Join(baseDir, logDirName)); err == nil {
+            fmt.Fprintf(w, "Журналы в %s сохранены, для их удаления добавьте --logs\n", filepath.Join(baseDir, logDirName))
+        }
+    }
+    return code
+}
+
+// runningBotPID возвращает PID из launcher.pid, если этот процесс жив и
+// похож на бота. Процесс с чужой командной строкой — это переиспользованный
+// PID, он очистке не мешает.
+func runningBotPID(pidPath, baseDir string) int {
+    data, err := os.ReadFile(pidPath)
+    if err != nil {
+        return 0
+    }
+    pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+    if err != nil || pid <= 0 || !processAlive(pid) {
+        return 0
+    }
+    cmdline, err := processCommandLine(pid)
+    if err != nil || commandLineMentions(cmdline, baseDir) || strings.Contains(strings.ToLower(cmdline), "python") {
+        return pid
+    }
+    return 0
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "strings"
+    "time"
+)
+
+var errUsage = errors.New("неверные аргументы командной строки")
+
+type options struct {
+    maxRestarts       int
+    skipVersionCheck  bool
+    shutdownTimeout   time.Duration
+    envOverride       bool
+    installDeps       bool
+    service           string
+    scriptArgs        []string
+    showVersion       bool
+    bootstrapPython   bool
+    tray              bool
+    logStream         string
+    healthAddr        string
+    logFormat         logFormat
+    script            string
+    captureOutput     bool
+    verbose           bool
+    app               string
+    noUpdate          bool
+    verifyHash        bool
+    readyTimeout      time.Duration
+    cleanEnv          bool
+    eventLog          string
+    eventLogForward   string
+    unbuffered        bool
+    showConsole       bool
+    module            string
+    dashboard         bool
+    doctor            bool
+    dryRun            bool
+    takeover          bool
+    python            string
+    registerStartup   bool
+    unregisterStartup bool
+    checkDeps         bool
+    elevate           bool
+    watch             bool
+    packages          string
+    cleanup           bool
+    cleanupLogs       bool
+    install           bool
+    uninstall         bool
+    shortcuts         string
+
+    allowExternalScript bool
+}
+
+const usageText = `Лаунчер DataMatrix/ЕГАИС бота.
+
+Находит интерпретатор Python и запускает скрипт бота %s
+рядом с исполняемым файлом лаунчера.
+
+Использование:
+  launcher [параметры] [-- аргументы бота]
+
+Всё, что указано после --, передаётся скрипту бота без изменений,
+например: launcher --max-restarts 3 -- --config prod --debug
+Аргументы с пробелами заключайте в кавычки.
+
+Конфигурация:
+  Необязательный файл launcher.json рядом с лаунчером:
+    python_path  путь к интерпретатору (отключает автоматический поиск)
+    required_python_bits  32 или 64 — требуемая разрядность Python
+    script_path  путь к скрипту бота
+    module       модуль Python вместо скрипта, то же, что --module
+    working_dir  рабочий каталог бота (по умолчанию папка скрипта)
+    required_files  файлы и папки, обязательные рядом со скриптом бота
+    clean_env    true — то же, что --clean-env
+    python_utf8  false — запускать бота с PYTHONUTF8=0 (по умолчанию 1)
+    python_io_encoding  значение PYTHONIOENCODING, например cp1251:replace
+    locale       значение LC_ALL и LANG для бота, например ru_RU.CP1251
+    proxy_url    прокси для бота (HTTP_PROXY и HTTPS_PROXY)
+    no_proxy     адреса без прокси (NO_PROXY)
+    proxy_from_system  true — взять прокси из настроек Windows
+    eventlog_forward  то же, что --eventlog-forward
+    env          объект с переменными окружения бота; значения могут
+                 ссылаться на другие переменные как ${ИМЯ} и заменяют
+                 унаследованные и заданные в .env (extra_env — прежнее имя)
+    credentials  объект «переменная: имя учётных данных» для секретов из
+                 диспетчера учётных данных Windows
+    max_restarts число перезапусков подряд после аварийного выхода бота
+    restart_window_seconds  считать сбои за это окно, а не подряд
+    restart_backoff_min_seconds, restart_backoff_max_seconds  границы паузы
+    restart_action  exit, notify-and-wait или reboot-bot-only (см. ниже)
+    restart_at   ежедневный плановый перезапуск бота, местное время ЧЧ:ММ
+    watchdog_max_memory_mb, watchdog_max_cpu_percent  пороги памяти и
+                 процессора для перезапуска бота (по умолчанию выключены)
+    watchdog_interval_seconds  период опроса (по умолчанию 30)
+    watchdog_sustain_seconds   сколько порог должен держаться (300)
+    shutdown_timeout_seconds  время на корректное завершение бота, с
+    install_deps true — то же, что --install-deps
+    bootstrap_python_url     адрес zip-архива встраиваемого Python
+    bootstrap_python_sha256  SHA-256 этого архива (обязателен для
+                             --bootstrap-python)
+    health_addr  то же, что --health-addr
+    log_stream_addr  то же, что --log-stream
+    capture_output  true — то же, что --capture-output
+    log_retention_days  удалять журналы старше N дней (по умолчанию 14,
+                        0 — не удалять)
+    logs_max_size_mb    предельный размер папки logs, МБ (по умолчанию 200)
+    crash_log_lines  строк stderr в отчёте logs\crash-*.log при сбое бота
+                     (по умолчанию 200, 0 — не сохранять отчёты)
+    unbuffered   true — то же, что --unbuffered
+    update_manifest_url  адрес манифеста обновлений (см. ниже)
+    verify_hash  true — то же, что --verify-hash
+    ready_timeout_seconds  то же, что --ready-timeout, в секундах
+    ready_file    файл готовности (по умолчанию bot.ready)
+    ready_marker  строка в stdout бота, означающая готовность
+    pre_launch   команда перед запуском бота; ошибка отменяет запуск
+    post_exit    команда после завершения бота, получает его код
+  Относительные пути считаются от каталога лаунчера. В Windows те же
+  ключи читаются из реестра HKLM\Software\EGAISBot\Launcher (REG_SZ,
+  REG_DWORD, REG_MULTI_SZ, объекты — подразделами); launcher.json важнее.
+
+  Необязательный файл .env рядом с лаунчером со строками KEY=VALUE
+  (пустые строки и # комментарии пропускаются) дополняет окружение бота.
+  Переменные, уже заданные в системе, имеют приоритет, если не указан
+  --env-override.
+
+Перед запуском лаунчер проверяет, что версия Python не ниже 3.11.
+Одновременно может работать только один экземпляр: лаунчер создаёт
+файл launcher.lock со своим PID и снимает блокировку, оставшуюся от
+аварийно завершённого процесса. С --takeover работающий экземпляр
+останавливается (сначала корректно, затем принудительно), и запуск
+продолжается; одновременно перехватить блокировку может только один.
+PID работающего процесса бота записывается в launcher.pid и
+обновляется при каждом перезапуске. Если при запуске в launcher.pid
+остался работающий процесс с нашим скриптом в командной строке
+(лаунчер аварийно завершился, а бот нет), он останавливается перед
+запуском нового экземпляра.
+
+Установка зависимостей (--install-deps):
+  Если рядом с лаунчером есть requirements.txt, а файла .deps_installed
+  ещё нет, выполняется python -m pip install -r requirements.txt.
+  После успешной установки создаётся .deps_installed; удалите его,
+  чтобы установить зависимости заново.
+
+Загрузка Python (--bootstrap-python, только Windows):
+  Если интерпретатор не найден, лаунчер скачивает встраиваемый
+  дистрибутив Python (по умолчанию 3.11.9 amd64 с python.org),
+  проверяет SHA-256, распаковывает его в python\ и повторяет поиск.
+
+Служба Windows:
+  launcher --service install    зарегистрировать службу с автозапуском
+                                (остальные параметры командной строки
+                                сохраняются для службы)
+  launcher --service uninstall  удалить службу
+  launcher --service run        используется диспетчером служб
+  Служба пишет события в журнал Windows «Приложение». Требуются права
+  администратора.
+
+Автозапуск при входе (--register-startup, только Windows):
+  launcher --register-startup --tray   создать или обновить задачу
+                                       EGAISBotLauncher в Планировщике
+                                       заданий с аргументами --tray
+  launcher --unregister-startup        удалить задачу
+  Для задачи «при входе» Планировщик обычно требует прав администратора.
+
+Ярлыки (--install, только Windows):
+  launcher --install --tray      создать ярлыки «EGAIS DataMatrix Bot» на
+                                 рабочем столе и в меню «Пуск», запускающие
+                                 лаунчер с аргументами --tray
+  launcher --install --shortcuts desktop
+                                 только на рабочем столе
+  launcher --uninstall           удалить ярлыки и значок
+  Ярлыки создаются для текущего пользователя, права администратора
+  не нужны.
+
+Журнал событий Windows (--eventlog-forward):
+  launcher --eventlog install    зарегистрировать источник EGAISBot
+                                 (требуются права администратора)
+  launcher --eventlog uninstall  удалить источник
+  С --eventlog-forward errors строки stderr бота уровня ERROR/CRITICAL
+  и трассировки исключений попадают в журнал «Приложение» как ошибки;
+  с all пересылаются все строки (WARNING — предупреждения, прочие —
+  сведения). Без зарегистрированного источника пересылка пропускается.
+
+Пробный запуск (--dry-run):
+  Выполняет все проверки обычного запуска и печатает интерпретатор,
+  рабочий каталог, командную строку бота и отличия его окружения от
+  окружения лаунчера, не запуская бота, хуки и pip. Ошибки дают те же
+  коды выхода, что и при обычном запуске.
+
+Диагностика (--doctor):
+  Проверяет установку, не запуская бота: каталог лаунчера, скрипт или
+  модуль бота, кандидатов в интерпретаторы и выбранный Python, пакеты из
+  requirements.txt, свободное место на диске и итоговое окружение бота
+  (секреты скрыты). При критической ошибке код выхода 1.
+
+Наблюдение за файлами (--watch):
+  После изменения .py-файлов в папке бота (или в рабочем каталоге для
+  --module) бот корректно перезапускается через секунду после последнего
+  сохранения. Пустой или синтаксически неверный файл перезапуска не
+  вызывает. По умолчанию выключено.
+
+Панель состояния (--dashboard):
+  Раз в секунду перерисовывает в терминале состояние бота, PID, время
+  работы, число перезапусков и последние строки журнала. Ctrl+C
+  останавливает бота и лаунчер.
+
+Значок в трее (--tray, только Windows):
+  Цвет значка показывает состояние бота: зелёный — работает, жёлтый —
+  перезапускается, красный — остановлен после сбоя. Меню позволяет
+  посмотреть состояние, перезапустить бота, открыть папку журналов и
+  выйти (бот при этом корректно останавливается).
+
+Проверка состояния (--health-addr):
+  GET http://адрес/ возвращает JSON {status, pid, uptime_seconds,
+  restarts}: код 200, пока бот работает, и 503, если он остановлен или
+  перезапускается. Сервер слушает только указанный адрес.
+
+Журнал:
+  Лаунчер пишет журнал в logs\launcher.log. С --log-format json каждое
+  событие выводится в консоль и в файл одной JSON-строкой с полями
+  event, timestamp, interpreter, exit_code, restart_count, message.
+  С --capture-output вывод бота сохраняется в logs\bot-stdout.log и
+  logs\bot-stderr.log (с отметкой времени у каждой строки, ротация по
+  10 МБ) и, если есть консоль, дублируется в неё.
+
+Готовность (--ready-timeout):
+  Бот сообщает о готовности, создав файл из переменной EGAIS_READY_FILE
+  (ready_file) или напечатав в stdout строку с ready_marker. Если сигнал
+  не пришёл вовремя, бот останавливается и считается аварийно завершённым
+  (код 16): дальше действует обычная политика перезапуска.
+
+Проверка целостности (--verify-hash):
+  manifest.sha256 в папке скрипта — строки "<sha256>  <путь>" в формате
+  sha256sum; последняя строка "# signature: <base64>" — подпись
+  Ed25519 всех предыдущих строк, проверяемая открытым ключом из сборки
+  (-X main.signingPublicKey). Скрипт бота должен быть в манифесте; при
+  несовпадении лаунчер называет первый несовпавший файл и не запускает
+  бота.
+
+Обновления (update_manifest_url):
+  При запуске лаунчер в фоне загружает JSON {version, url, sha256}. Если
+  version новее версии лаунчера, zip-пакет скачивается, проверяется по
+  SHA-256 и распаковывается в update\staged. При следующем запуске его
+  содержимое копируется поверх каталога установки, прежние файлы
+  сохраняются в update\backup. Ошибка загрузки не меняет установку.
+  Манифест должен содержать поле signature — подпись Ed25519, которая
+  проверяется открытым ключом из сборки (-X main.signingPublicKey); без
+  ключа или без верной подписи обновление не загружается.
+
+Перезапуск:
+  Если бот завершился с ненулевым кодом, лаунчер перезапускает его
+  с паузой от 1 до 60 секунд (удваивается с каждой попыткой).
+  Если бот проработал больше 30 секунд, пауза и счётчик сбрасываются.
+  Завершение с кодом 0 перезапуска не вызывает.
+  Когда перезапуски исчерпаны, restart_action определяет дальнейшее:
+    exit             завершить лаунчер с кодом бота (по умолчанию)
+    notify-and-wait  показать уведомление и ждать перезапуска из трея
+    reboot-bot-only  после паузы начать новую серию попыток
+
+Остановка:
+  Ctrl+C и SIGTERM передаются боту (в Windows как CTRL_BREAK). Если бот
+  не завершился за отведённое время, процесс завершается принудительно.
+  Если у лаунчера нет своей консоли (сборка -H windowsgui, служба), бот
+  запускается со скрытой консолью и окно не появляется даже для
+  python.exe; --show-console оставляет окно видимым.
+
+Порядок поиска интерпретатора Python:
+  0. --python, EGAIS_PYTHON или python_path: если интерпретатор задан
+     явно, поиск не выполняется, а отсутствующий или неисполняемый файл
+     сразу даёт код 10. Имя без каталога ищется в PATH.
+  1. .venv\Scripts\pythonw.exe и .venv\Scripts\python.exe рядом с лаунчером
+     (.venv/bin/python3 и .venv/bin/python в Linux и macOS)
+  2. python\pythonw.exe рядом с лаунчером
+  3. python\python.exe рядом с лаунчером
+  4. pythonw из PATH
+  5. python из PATH
+  В Linux и macOS вместо пунктов 2–5: python/bin/python3,
+  python/bin/python, затем python3 и python из PATH. Файл без права на
+  выполнение пропускается с предупреждением.
+  В Windows перед поиском PATH дополняется актуальными значениями Path
+  из реестра, поэтому перезагрузка после установки Python не нужна.
+  Для интерпретатора из .venv процессу бота выставляется VIRTUAL_ENV,
+  а каталог Scripts добавляется в начало PATH.
+
+Выбор скрипта бота (по убыванию приоритета):
+  --script, --app <имя> (<имя>\main.py), переменная EGAIS_BOT_SCRIPT,
+  script_path из launcher.json, bot_app\main.py. Скрипт из --script и
+  EGAIS_BOT_SCRIPT должен лежать внутри каталога лаунчера, если не
+  указан --allow-external-script.
+
+Коды выхода:
+  0   бот завершился штатно
+  1   внутренняя ошибка лаунчера или не удалось запустить процесс
+  4   неверные аргументы командной строки или параметр, доступный
+      только в Windows, на другой ОС
+  10  не найден интерпретатор Python или он не запускается
+  11  не найден или повреждён скрипт (модуль) бота
+  12  бот уже запущен другим экземпляром лаунчера
+  13  версия Python ниже 3.11
+  14  не удалось установить зависимости
+  15  файлы бота не прошли проверку целостности
+  16  бот не сообщил о готовности за --ready-timeout
+  17  хук pre_launch завершился с ошибкой
+  18  секрет не найден в диспетчере учётных данных
+  19  разрядность Python не совпадает с required_python_bits
+  Прочие коды — код завершения бота после исчерпания перезапусков.
+
+Переменные окружения:
+  EGAIS_BOT_SCRIPT  путь к скрипту бота
+  EGAIS_PYTHON      интерпретатор Python, то же, что --python
+  PATH        используется для поиска python/pythonw
+  VIRTUAL_ENV выставляется для процесса бота при запуске из .venv
+  PYTHONUTF8  выставляется в 1 для процесса бота (0 при "python_utf8": false)
+  PYTHONIOENCODING, LC_ALL, LANG
+              выставляются из python_io_encoding и locale в launcher.json
+
+Параметры:
+  -h, --help
+    	показать эту справку и выйти
+`
+
+func newFlagSet(opts *options, output io.Writer) *flag.FlagSet {
+    fs := flag.NewFlagSet("launcher", flag.ContinueOnError)
+    fs.SetOutput(output)
+    fs.Usage = func() {
+        fmt.Fprintf(output, usageText, defaultScriptRel)
+        fs.PrintDefaults()
+    }
+    fs.BoolVar(&opts.showVersion, "version", false, "показать версию лаунчера и выйти")
+    fs.BoolVar(&opts.skipVersionCheck, "skip-version-check", false, "не проверять, что версия Python не ниже 3.11")
+    fs.StringVar(&opts.eventLog, "eventlog", "", "регистрация источника журнала событий Windows: install или uninstall")
+    fs.StringVar(&opts.eventLogForward, "eventlog-forward", "", "пересылать stderr бота в журнал событий Windows: errors или all")
+    fs.StringVar(&opts.service, "service", "", "управление службой Windows: install, uninstall или run")
+    fs.Func("log-format", "формат журнала лаунчера: text (по умолчанию) или json", func(value string) error {
+        switch logFormat(value) {
+        case logFormatText, logFormatJSON:
+            opts.logFormat = logFormat(value)
+            return nil
+        }
+        return fmt.Errorf("ожидается text или json")
+    })
+    fs.StringVar(&opts.app, "app", "", "имя папки бота рядом с лаунчером: запускается <app>\\main.py (по умолчанию bot_app)")
+    fs.StringVar(&opts.module, "module", "", "запустить модуль Python (python -m <модуль>) вместо скрипта")
+    fs.StringVar(&opts.script, "script", "", "путь к скрипту бота (по умолчанию bot_app\\main.py или "+scriptEnvVar+")")
+    fs.BoolVar(&opts.allowExternalScript, "allow-external-script", false, "разрешить --script и "+scriptEnvVar+" вне каталога лаунчера")
+    fs.BoolVar(&opts.verifyHash, "verify-hash", false, "перед запуском сверить файлы бота с подписанным manifest.sha256")
+    fs.BoolVar(&opts.noUpdate, "no-update", false, "не проверять обновления, даже если задан update_manifest_url")
+    fs.BoolVar(&opts.verbose, "verbose", false, "подробно журналировать выбор интерпретатора, команду запуска и окружение")
+    fs.BoolVar(&opts.verbose, "debug", false, "то же, что --verbose")
+    fs.BoolVar(&opts.captureOutput, "capture-output", false, "сохранять stdout и stderr бота в logs\\bot-stdout.log и logs\\bot-stderr.log")
+    fs.BoolVar(&opts.showConsole, "show-console", false, "не скрывать консольное окно бота, если у лаунчера нет консоли (для отладки)")
+    fs.BoolVar(&opts.unbuffered, "unbuffered", false, "запускать Python с -u, чтобы вывод бота появлялся сразу")
+    fs.StringVar(&opts.healthAddr, "health-addr", "", "адрес HTTP-проверки состояния, например 127.0.0.1:8787")
+    fs.StringVar(&opts.logStream, "log-stream", "", "транслировать вывод бота TCP-клиентам на этом адресе, например 127.0.0.1:9999")
+    fs.BoolVar(&opts.dashboard, "dashboard", false, "показывать в терминале обновляемую панель состояния бота")
+    fs.BoolVar(&opts.registerStartup, "register-startup", false, "создать задачу Планировщика заданий, запускающую лаунчер при входе пользователя с остальными аргументами")
+    fs.BoolVar(&opts.unregisterStartup, "unregister-startup", false, "удалить задачу автозапуска из Планировщика заданий")
+    fs.BoolVar(&opts.watch, "watch", false, "перезапускать бота после изменения .py-файлов в его каталоге (для отладки на месте)")
+    fs.BoolVar(&opts.elevate, "elevate", false, "если лаунчер запущен без прав администратора, перезапустить его с ними через запрос UAC")
+    fs.BoolVar(&opts.checkDeps, "check-deps", false, "проверить, что пакеты из requirements.txt установлены и импортируются, ничего не устанавливая")
+    fs.StringVar(&opts.packages, "packages", "", "для --check-deps: проверить эти пакеты через запятую вместо requirements.txt")
+    fs.BoolVar(&opts.cleanup, "cleanup", false, "удалить файлы, созданные лаунчером (PID, блокировка, состояние, отметки, скачанные обновления), если бот не запущен")
+    fs.BoolVar(&opts.cleanupLogs, "logs", false, "вместе с --cleanup удалить и каталог logs")
+    fs.BoolVar(&opts.install, "install", false, "создать ярлыки лаунчера с остальными аргументами (см. --shortcuts)")
+    fs.BoolVar(&opts.uninstall, "uninstall", false, "удалить ярлыки, созданные --install")
+    fs.StringVar(&opts.shortcuts, "shortcuts", "desktop,start-menu", "где создавать ярлыки для --install: desktop, start-menu или оба через запятую")
+    fs.StringVar(&opts.python, "python", "", "использовать указанный интерпретатор Python без поиска (то же, что EGAIS_PYTHON)")
+    fs.BoolVar(&opts.takeover, "takeover", false, "если бот уже запущен другим лаунчером, остановить его и запуститься вместо него")
+    fs.BoolVar(&opts.dryRun, "dry-run", false, "выполнить все проверки и вывести команду запуска и окружение бота, не запуская его")
+    fs.BoolVar(&opts.doctor, "doctor", false, "проверить установку и окружение и вывести отчёт, не запуская бота")
+    fs.BoolVar(&opts.tray, "tray", false, "показать значок в области уведомлений Windows с меню управления ботом")
+    fs.BoolVar(&opts.bootstrapPython, "bootstrap-python", false, "если Python не найден, скачать встраиваемый Python в каталог python")
+    fs.BoolVar(&opts.installDeps, "install-deps", false, "при первом запуске установить зависимости из requirements.txt через pip")
+    fs.BoolVar(&opts.cleanEnv, "clean-env", false, "не передавать боту окружение лаунчера, кроме SystemRoot и PATH")
+    fs.BoolVar(&opts.envOverride, "env-override", false, "значения из .env заменяют уже заданные переменные окружения")
+    fs.DurationVar(&opts.readyTimeout, "ready-timeout", 0, "сколько ждать сигнала готовности от бота, например 60s (по умолчанию не ждать)")
+    fs.DurationVar(&opts.shutdownTimeout, "shutdown-timeout", 0, "сколько ждать корректного завершения бота после Ctrl+C/SIGTERM, например 15s (по умолчанию 10s)")
+    fs.IntVar(&opts.maxRestarts, "max-restarts", -1, "сколько раз подряд перезапускать упавший бот (по умолчанию 5 или max_restarts из конфигурации)")
+    return fs
+}
+
+func parseArgs(args []string, output io.Writer) (*options, error) {
+    opts := &options{logFormat: logFormatText}
+    fs := newFlagSet(opts, output)
+    if err := fs.Parse(args); err != nil {
+        return nil, err
+    }
+    switch opts.service {
+    case "", "install", "uninstall", "run":
+    default:
+        fmt.Fprintf(output, "Неизвестная команда службы: %s (ожидается install, uninstall или run)\n", opts.service)
+        return nil, errUsage
+    }
+    switch opts.eventLog {
+    case "", "install", "uninstall":
+    default:
+        fmt.Fprintf(output, "Неизвестная команда журнала событий: %s (ожидается install или uninstall)\n", opts.eventLog)
+        return nil, errUsage
+    }
+    if opts.registerStartup && opts.unregisterStartup {
+        fmt.Fprintln(output, "--register-startup и --unregister-startup нельзя указывать вместе")
+        return nil, errUsage
+    }
+    if opts.packages != "" && !opts.checkDeps {
+        fmt.Fprintln(output, "--packages указывается только вместе с --check-deps")
+        return nil, errUsage
+    }
+    if opts.cleanupLogs && !opts.cleanup {
+        fmt.Fprintln(output, "--logs указывается только вместе с --cleanup")
+        return nil, errUsage
+    }
+    if opts.install && opts.uninstall {
+        fmt.Fprintln(output, "--install и --uninstall нельзя указывать вместе")
+        return nil, errUsage
+    }
+    for _, loc := range strings.Split(opts.shortcuts, ",") {
+        switch strings.TrimSpace(loc) {
+        case "desktop", "start-menu":
+        default:
+            fmt.Fprintf(output, "--shortcuts: неизвестное расположение %q (ожидается desktop или start-menu)\n", loc)
+            return nil, errUsage
+        }
+    }
+    if err := validEventForwardMode(opts.eventLogForward); err != nil {
+        fmt.Fprintf(output, "--eventlog-forward: %v\n", err)
+        return nil, errUsage
+    }
+    if fs.NArg() > 0 {
+        if consumed := len(args) - fs.NArg(); consumed == 0 || args[consumed-1] != "--" {
+            fmt.Fprintf(output, "Неизвестный аргумент: %s (аргументы для бота указываются после --)\n", fs.Arg(0))
+            fs.Usage()
+            return nil, errUsage
+        }
+        opts.scriptArgs = fs.Args()
+    }
+    return opts, nil
+}
+
+// stripFlag удаляет из args флаг name вместе с его значением в любой из
+// форм -name v, --name v, -name=v, --name=v.
+func stripFlag(args []string, name string) []string {
+    out := make([]string, 0, len(args))
+    for i := 0; i < len(args); i++ {
+        arg := args[i]
+        if arg == "--" {
+            return append(out, args[i:]...)
+        }
+        trimmed := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+        if trimmed == arg {
+            out = append(out, arg)
+            continue
+        }
+        if trimmed == name {
+            i++
+            continue
+        }
+        if strings.HasPrefix(trimmed, name+"=") {
+            continue
+        }
+        out = append(out, arg)
+    }
+    return out
+}
+
+// stripBoolFlag удаляет из args логический флаг name в формах -name,
+// --name, -name=true и --name=false.
+func stripBoolFlag(args []string, name string) []string {
+    out := make([]string, 0, len(args))
+    for i, arg := range args {
+        if arg == "--" {
+            return append(out, args[i:]...)
+        }
+        trimmed := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+        if trimmed != arg && (trimmed == name || strings.HasPrefix(trimmed, name+"=")) {
+            continue
+        }
+        out = append(out, arg)
+    }
+    return out
+}
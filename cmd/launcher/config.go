@@ -0,0 +1,182 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+const configFileName = "launcher.json"
+
+type Config struct {
+    PythonPath         string            `json:"python_path"`
+    RequiredPythonBits int               `json:"required_python_bits"`
+    ScriptPath         string            `json:"script_path"`
+    Module             string            `json:"module"`
+    WorkingDir         string            `json:"working_dir"`
+    RequiredFiles      []string          `json:"required_files"`
+    Env                map[string]string `json:"env"`
+    ExtraEnv           map[string]string `json:"extra_env"`
+    Credentials        map[string]string `json:"credentials"`
+    CleanEnv           bool              `json:"clean_env"`
+    PythonUTF8         *bool             `json:"python_utf8"`
+    PythonIOEncoding   string            `json:"python_io_encoding"`
+    Locale             string            `json:"locale"`
+
+    ProxyURL           string `json:"proxy_url"`
+    NoProxy            string `json:"no_proxy"`
+    ProxyFromSystem    bool   `json:"proxy_from_system"`
+    MaxRestarts        *int   `json:"max_restarts"`
+    ShutdownTimeoutSec *int   `json:"shutdown_timeout_seconds"`
+    InstallDeps        bool   `json:"install_deps"`
+
+    RestartWindowSec int    `json:"restart_window_seconds"`
+    BackoffMinSec    int    `json:"restart_backoff_min_seconds"`
+    BackoffMaxSec    int    `json:"restart_backoff_max_seconds"`
+    RestartAction    string `json:"restart_action"`
+    RestartAt        string `json:"restart_at"`
+
+    WatchdogMaxMemoryMB   int     `json:"watchdog_max_memory_mb"`
+    WatchdogMaxCPUPercent float64 `json:"watchdog_max_cpu_percent"`
+    WatchdogIntervalSec   int     `json:"watchdog_interval_seconds"`
+    WatchdogSustainSec    int     `json:"watchdog_sustain_seconds"`
+
+    BootstrapPythonURL    string `json:"bootstrap_python_url"`
+    BootstrapPythonSHA256 string `json:"bootstrap_python_sha256"`
+
+    HealthAddr       string `json:"health_addr"`
+    LogStreamAddr    string `json:"log_stream_addr"`
+    CaptureOutput    bool   `json:"capture_output"`
+    LogRetentionDays *int   `json:"log_retention_days"`
+    LogsMaxSizeMB    *int   `json:"logs_max_size_mb"`
+    CrashLogLines    *int   `json:"crash_log_lines"`
+    Unbuffered       bool   `json:"unbuffered"`
+    EventLogForward  string `json:"eventlog_forward"`
+
+    UpdateManifestURL string `json:"update_manifest_url"`
+    VerifyHash        bool   `json:"verify_hash"`
+
+    ReadyTimeoutSec int    `json:"ready_timeout_seconds"`
+    ReadyFile       string `json:"ready_file"`
+    ReadyMarker     string `json:"ready_marker"`
+
+    PreLaunch string `json:"pre_launch"`
+    PostExit  string `json:"post_exit"`
+}
+
+// loadConfig читает настройки из реестра (только Windows) и поверх них из
+// launcher.json: ключ, заданный в файле, важнее значения из реестра, а
+// объекты env и credentials объединяются по ключам. Если файл повреждён,
+// остаются настройки из реестра.
+func loadConfig(baseDir string) (*Config, error) {
+    cfg := &Config{}
+    for _, w := range readRegistryConfig(cfg) {
+        logger.Printf("Предупреждение: значение реестра пропущено: %v", w)
+    }
+    path := filepath.Join(baseDir, configFileName)
+    data, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return cfg, nil
+    }
+    if err != nil {
+        return cfg, fmt.Errorf("чтение %s: %w", path, err)
+    }
+    if err := json.Unmarshal(data, cfg); err != nil {
+        cfg = &Config{}
+        readRegistryConfig(cfg)
+        return cfg, fmt.Errorf("разбор %s: %w", path, err)
+    }
+    return cfg, nil
+}
+
+func resolvePath(baseDir, path string) string {
+    if path == "" || filepath.IsAbs(path) {
+        return path
+    }
+    return filepath.Join(baseDir, path)
+}
+
+// envVars объединяет env и устаревший extra_env (env важнее) и раскрывает
+// ссылки ${VAR} в значениях. Ссылки ищутся сначала среди переменных
+// конфигурации, затем в env — окружении, собранном до этого шага.
+// Неизвестные переменные и циклические ссылки раскрываются в пустую строку
+// и возвращаются в warnings.
+func (c *Config) envVars(env []string) (vars []envVar, warnings []error) {
+    values := make(map[string]string, len(c.Env)+len(c.ExtraEnv))
+    names := make(map[string]string, len(values))
+    for _, m := range []map[string]string{c.ExtraEnv, c.Env} {
+        for key, value := range m {
+            if old, ok := names[envMapKey(key)]; ok {
+                delete(values, old)
+            }
+            names[envMapKey(key)] = key
+            values[key] = value
+        }
+    }
+
+    resolved := make(map[string]string, len(values))
+    resolving := make(map[string]bool)
+    var resolve func(key string) string
+    resolve = func(key string) string {
+        if v, ok := resolved[key]; ok {
+            return v
+        }
+        if resolving[key] {
+            warnings = append(warnings, fmt.Errorf("env: циклическая ссылка на %s", key))
+            return ""
+        }
+        resolving[key] = true
+        v := expandEnvRefs(values[key], func(ref string) string {
+            if name, ok := names[envMapKey(ref)]; ok {
+                return resolve(name)
+            }
+            if v, ok := lookupEnv(env, ref); ok {
+                return v
+            }
+            warnings = append(warnings, fmt.Errorf("env: %s ссылается на незаданную переменную %s", key, ref))
+            return ""
+        })
+        delete(resolving, key)
+        resolved[key] = v
+        return v
+    }
+
+    for _, key := range sortedKeys(values) {
+        vars = append(vars, envVar{key: key, value: resolve(key)})
+    }
+    return vars, warnings
+}
+
+// expandEnvRefs заменяет ${NAME} результатом lookup. Одиночный $ без
+// фигурных скобок остаётся как есть, чтобы не портить пароли и регулярные
+// выражения; $${ даёт буквальное ${.
+func expandEnvRefs(value string, lookup func(string) string) string {
+    if !strings.Contains(value, "${") {
+        return value
+    }
+    var b strings.Builder
+    for {
+        i := strings.Index(value, "${")
+        if i < 0 {
+            b.WriteString(value)
+            return b.String()
+        }
+        if i > 0 && value[i-1] == '$' {
+            b.WriteString(value[:i])
+            b.WriteString("{")
+            value = value[i+2:]
+            continue
+        }
+        end := strings.IndexByte(value[i+2:], '}')
+        if end < 0 {
+            b.WriteString(value)
+            return b.String()
+        }
+        b.WriteString(value[:i])
+        b.WriteString(lookup(value[i+2 : i+2+end]))
+        value = value[i+3+end:]
+    }
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// readRegistryConfig — реестр есть только в Windows.
+func readRegistryConfig(cfg *Config) []error { return nil }
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestExpandEnvRefs(t *testing.T) {
+    vars := map[string]string{"HOME": `C:\Users\kassa`, "PORT": "8080", "EMPTY": ""}
+    lookup := func(name string) string { return vars[name] }
+    tests := []struct {
+        value string
+        want  string
+    }{
+        {"plain", "plain"},
+        {"${HOME}\\bot", `C:\Users\kassa\bot`},
+        {"http://localhost:${PORT}/api", "http://localhost:8080/api"},
+        {"${HOME}:${PORT}", `C:\Users\kassa:8080`},
+        {"${MISSING}x", "x"},
+        {"a${EMPTY}b", "ab"},
+        {"pa$$word", "pa$$word"},
+        {"$HOME", "$HOME"},
+        {"$${HOME}", "${HOME}"},
+        {"${HOME", "${HOME"},
+        {"", ""},
+    }
+    for _, tt := range tests {
+        if got := expandEnvRefs(tt.value, lookup); got != tt.want {
+            t.Errorf("expandEnvRefs(%q) = %q, ожидалось %q", tt.value, got, tt.want)
+        }
+    }
+}
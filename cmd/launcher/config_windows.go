@@ -0,0 +1,144 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+
+    "golang.org/x/sys/windows/registry"
+)
+
+const registryConfigPath = `Software\EGAISBot\Launcher`
+
+// readRegistryConfig заполняет cfg из HKLM\Software\EGAISBot\Launcher,
+// куда настройки раскладывает групповая политика. Имена значений
+// совпадают с ключами launcher.json: строки — REG_SZ, числа и флаги —
+// REG_DWORD, списки — REG_MULTI_SZ, объекты (env, credentials) —
+// подразделы со значениями REG_SZ. Ключ читается из 64-битного
+// представления реестра независимо от разрядности лаунчера. Значения
+// неверного типа пропускаются и возвращаются в warnings.
+func readRegistryConfig(cfg *Config) (warnings []error) {
+    k, err := registry.OpenKey(registry.LOCAL_MACHINE, registryConfigPath, registry.QUERY_VALUE|registry.WOW64_64KEY)
+    if errors.Is(err, registry.ErrNotExist) {
+        return nil
+    }
+    if err != nil {
+        return []error{fmt.Errorf(`HKLM\%s: %w`, registryConfigPath, err)}
+    }
+    defer k.Close()
+
+    v := reflect.ValueOf(cfg).Elem()
+    for i := 0; i < v.NumField(); i++ {
+        name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+        if name == "" || name == "-" {
+            continue
+        }
+        if err := readRegistryField(k, name, v.Field(i)); err != nil && !errors.Is(err, registry.ErrNotExist) {
+            warnings = append(warnings, fmt.Errorf(`HKLM\%s\%s: %w`, registryConfigPath, name, err))
+        }
+    }
+    return warnings
+}
+
+func readRegistryField(k registry.Key, name string, field reflect.Value) error {
+    switch field.Kind() {
+    case reflect.String:
+        s, _, err := k.GetStringValue(name)
+        if err != nil {
+            return err
+        }
+        field.SetString(s)
+    case reflect.Bool:
+        n, _, err := k.GetIntegerValue(name)
+        if err != nil {
+            return err
+        }
+        field.SetBool(n != 0)
+    case reflect.Int:
+        n, err := registryInt(k, name)
+        if err != nil {
+            return err
+        }
+        field.SetInt(int64(n))
+    case reflect.Pointer:
+        switch field.Type().Elem().Kind() {
+        case reflect.Int:
+            n, err := registryInt(k, name)
+            if err != nil {
+                return err
+            }
+            field.Set(reflect.ValueOf(&n))
+        case reflect.Bool:
+            n, _, err := k.GetIntegerValue(name)
+            if err != nil {
+                return err
+            }
+            b := n != 0
+            field.Set(reflect.ValueOf(&b))
+        }
+    case reflect.Float64:
+        // REG_DWORD не хранит дробную часть, поэтому допускается и REG_SZ.
+        if n, _, err := k.GetIntegerValue(name); err == nil {
+            field.SetFloat(float64(n))
+            return nil
+        }
+        s, _, err := k.GetStringValue(name)
+        if err != nil {
+            return err
+        }
+        f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+        if err != nil {
+            return err
+        }
+        field.SetFloat(f)
+    case reflect.Slice:
+        list, _, err := k.GetStringsValue(name)
+        if err != nil {
+            return err
+        }
+        field.Set(reflect.ValueOf(list))
+    case reflect.Map:
+        m, err := registryStringMap(k, name)
+        if err != nil {
+            return err
+        }
+        field.Set(reflect.ValueOf(m))
+    }
+    return nil
+}
+
+// registryInt читает REG_DWORD как знаковое число, чтобы, например,
+// max_restarts можно было задать как 0xffffffff (-1).
+func registryInt(k registry.Key, name string) (int, error) {
+    n, valType, err := k.GetIntegerValue(name)
+    if err != nil {
+        return 0, err
+    }
+    if valType == registry.DWORD {
+        return int(int32(n)), nil
+    }
+    return int(n), nil
+}
+
+func registryStringMap(parent registry.Key, name string) (map[string]string, error) {
+    k, err := registry.OpenKey(parent, name, registry.QUERY_VALUE|registry.WOW64_64KEY)
+    if err != nil {
+        return nil, err
+    }
+    defer k.Close()
+    names, err := k.ReadValueNames(0)
+    if err != nil {
+        return nil, err
+    }
+    m := make(map[string]string, len(names))
+    for _, valueName := range names {
+        s, _, err := k.GetStringValue(valueName)
+        if err != nil {
+            return nil, fmt.Errorf(`%s\%s: %w`, name, valueName, err)
+        }
+        m[valueName] = s
+    }
+    return m, nil
+}
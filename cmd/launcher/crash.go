@@ -0,0 +1,120 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    defaultCrashLogLines = 200
+    // maxTailLineLen ограничивает длину одной строки в буфере, чтобы бот,
+    // пишущий в stderr без переводов строк, не раздувал память лаунчера.
+    maxTailLineLen = 4096
+    crashLogPrefix = "crash-"
+)
+
+// stderrTail хранит последние lines строк stderr бота в кольцевом буфере.
+// Ошибки записи не возвращаются, чтобы не прерывать вывод бота в
+// io.MultiWriter.
+type stderrTail struct {
+    mu    sync.Mutex
+    lines []string
+    next  int
+    full  bool
+    line  []byte
+}
+
+func newStderrTail(lines int) *stderrTail {
+    return &stderrTail{lines: make([]string, lines)}
+}
+
+func (t *stderrTail) Write(p []byte) (int, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    for _, chunk := range bytes.SplitAfter(p, []byte{'\n'}) {
+        if room := maxTailLineLen - len(t.line); room > 0 {
+            t.line = append(t.line, chunk[:min(len(chunk), room)]...)
+        }
+        if bytes.HasSuffix(chunk, []byte{'\n'}) {
+            t.push(strings.TrimRight(string(t.line), "\r\n"))
+            t.line = t.line[:0]
+        }
+    }
+    return len(p), nil
+}
+
+func (t *stderrTail) push(line string) {
+    t.lines[t.next] = line
+    t.next = (t.next + 1) % len(t.lines)
+    if t.next == 0 {
+        t.full = true
+    }
+}
+
+// snapshot возвращает накопленные строки по порядку, включая
+// незавершённую последнюю.
+func (t *stderrTail) snapshot() []string {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    var out []string
+    if t.full {
+        out = append(out, t.lines[t.next:]...)
+    }
+    out = append(out, t.lines[:t.next]...)
+    if len(t.line) > 0 {
+        out = append(out, string(t.line))
+    }
+    return out
+}
+
+// reset очищает буфер перед новым запуском бота, чтобы в отчёт попадал
+// только вывод упавшего процесса.
+func (t *stderrTail) reset() {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    clear(t.lines)
+    t.next, t.full = 0, false
+    t.line = t.line[:0]
+}
+
+// crashReporter сохраняет logs\crash-<время>.log при аварийном завершении
+// бота: код выхода, интерпретатор, командную строку и хвост stderr.
+type crashReporter struct {
+    dir         string
+    tail        *stderrTail
+    interpreter string
+    commandLine string
+}
+
+func (r *crashReporter) write(code int, started time.Time) {
+    now := time.Now()
+    var b strings.Builder
+    fmt.Fprintf(&b, "Время сбоя: %s\n", now.Format(logTimestampLayout))
+    fmt.Fprintf(&b, "Код выхода: %d\n", code)
+    fmt.Fprintf(&b, "Время работы: %s\n", now.Sub(started).Round(time.Second))
+    fmt.Fprintf(&b, "Интерпретатор: %s\n", r.interpreter)
+    fmt.Fprintf(&b, "Команда: %s\n", r.commandLine)
+    fmt.Fprintf(&b, "Лаунчер: %s\n", versionString())
+    lines := r.tail.snapshot()
+    fmt.Fprintf(&b, "\nПоследние строки stderr (%d):\n", len(lines))
+    for _, line := range lines {
+        b.WriteString(line)
+        b.WriteString("\n")
+    }
+
+    path := filepath.Join(r.dir, crashLogPrefix+now.Format("20060102-150405")+".log")
+    if err := os.MkdirAll(r.dir, 0o755); err != nil {
+        logger.Printf("Не удалось сохранить отчёт о сбое: %v", err)
+        return
+    }
+    if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+        logger.Printf("Не удалось сохранить отчёт о сбое: %v", err)
+        return
+    }
+    logger.Printf("Отчёт о сбое сохранён в %s", path)
+}
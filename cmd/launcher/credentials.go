@@ -0,0 +1,19 @@
+package main
+
+import "fmt"
+
+// credentialEnv читает секреты из диспетчера учётных данных Windows по
+// отображению «переменная → имя учётных данных» из launcher.json.
+// Отсутствие любого секрета — ошибка: без него бот всё равно не сможет
+// работать.
+func credentialEnv(credentials map[string]string) ([]string, error) {
+    var env []string
+    for _, key := range sortedKeys(credentials) {
+        value, err := readCredential(credentials[key])
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", key, err)
+        }
+        env = append(env, key+"="+value)
+    }
+    return env, nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+func readCredential(target string) (string, error) {
+    return "", errors.New("диспетчер учётных данных доступен только в Windows; задайте переменную через окружение или .env")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "unicode/utf16"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+const credTypeGeneric = 1
+
+var (
+    modadvapi32  = windows.NewLazySystemDLL("advapi32.dll")
+    procCredRead = modadvapi32.NewProc("CredReadW")
+    procCredFree = modadvapi32.NewProc("CredFree")
+)
+
+// credentialW повторяет структуру CREDENTIALW из wincred.h.
+type credentialW struct {
+    Flags              uint32
+    Type               uint32
+    TargetName         *uint16
+    Comment            *uint16
+    LastWritten        windows.Filetime
+    CredentialBlobSize uint32
+    CredentialBlob     *byte
+    Persist            uint32
+    AttributeCount     uint32
+    Attributes         uintptr
+    TargetAlias        *uint16
+    UserName           *uint16
+}
+
+// readCredential возвращает пароль общих учётных данных target из
+// диспетчера учётных данных Windows текущего пользователя.
+func readCredential(target string) (string, error) {
+    name, err := windows.UTF16PtrFromString(target)
+    if err != nil {
+        return "", err
+    }
+    var cred *credentialW
+    r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+    if r == 0 {
+        if errors.Is(err, windows.ERROR_NOT_FOUND) {
+            return "", fmt.Errorf("учётные данные %q не найдены", target)
+        }
+        return "", fmt.Errorf("CredRead %q: %w", target, err)
+    }
+    defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+    blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+    return decodeCredentialBlob(blob), nil
+}
+
+// decodeCredentialBlob декодирует пароль: cmdkey и панель управления
+// сохраняют его в UTF-16LE, другие программы — как байты UTF-8.
+func decodeCredentialBlob(blob []byte) string {
+    if len(blob) >= 2 && len(blob)%2 == 0 && blob[1] == 0 {
+        u := make([]uint16, len(blob)/2)
+        for i := range u {
+            u[i] = uint16(blob[2*i]) | uint16(blob[2*i+1])<<8
+        }
+        return string(utf16.Decode(u))
+    }
+    return string(blob)
+}
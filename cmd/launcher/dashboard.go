@@ -0,0 +1,137 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    dashboardRefreshInterval = time.Second
+    dashboardLogLines        = 10
+    dashboardRule            = "------------------------------------------------------------"
+)
+
+// dashboard — текстовая панель для --dashboard: вместо прокрутки журнала
+// раз в секунду перерисовывает на месте состояние бота и последние строки
+// вывода лаунчера и бота. Использует только управляющие последовательности
+// ANSI, поэтому работает в обычном терминале и по SSH.
+type dashboard struct {
+    mu      sync.Mutex
+    out     io.Writer
+    status  *botStatus
+    lines   []string
+    partial []byte
+}
+
+// runDashboard запускает бота с панелью состояния. Ctrl+C по-прежнему
+// обрабатывает супервизор: бот получает прерывание, после чего панель
+// рисуется последний раз и лаунчер выходит.
+func runDashboard(opts *options, ctl *controller) int {
+    enableVirtualTerminal(os.Stdout)
+    fmt.Fprint(os.Stdout, "\x1b[2J")
+    d := &dashboard{out: os.Stdout, status: ctl.status}
+    logger.setConsole(d)
+    defer logger.setConsole(os.Stderr)
+    dashCtl := *ctl
+    dashCtl.console = d
+
+    stop := make(chan struct{})
+    rendered := make(chan struct{})
+    go func() {
+        defer close(rendered)
+        ticker := time.NewTicker(dashboardRefreshInterval)
+        defer ticker.Stop()
+        for {
+            d.render()
+            select {
+            case <-stop:
+                return
+            case <-ticker.C:
+            }
+        }
+    }()
+
+    code := launch(opts, &dashCtl)
+    close(stop)
+    <-rendered
+    d.render()
+    fmt.Fprintln(d.out)
+    return code
+}
+
+// Write собирает строки для нижней части панели. Ошибок не возвращает,
+// чтобы не прерывать вывод бота в io.MultiWriter.
+func (d *dashboard) Write(p []byte) (int, error) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.partial = append(d.partial, p...)
+    for {
+        i := bytes.IndexByte(d.partial, '\n')
+        if i < 0 {
+            break
+        }
+        d.lines = append(d.lines, strings.TrimRight(string(d.partial[:i]), "\r"))
+        d.partial = d.partial[i+1:]
+    }
+    if extra := len(d.lines) - dashboardLogLines; extra > 0 {
+        d.lines = append(d.lines[:0], d.lines[extra:]...)
+    }
+    return len(p), nil
+}
+
+func (d *dashboard) render() {
+    snap := d.status.snapshot()
+    d.mu.Lock()
+    lines := append([]string(nil), d.lines...)
+    d.mu.Unlock()
+
+    var b strings.Builder
+    b.WriteString("\x1b[H")
+    row := func(format string, args ...any) {
+        fmt.Fprintf(&b, format, args...)
+        b.WriteString("\x1b[K\n")
+    }
+    row("Бот ЕГАИС — %s", versionString())
+    row("%s", time.Now().Format("02.01.2006 15:04:05"))
+    row("%s", dashboardRule)
+    row("Состояние:      %s", dashboardState(snap.State))
+    if snap.PID > 0 {
+        row("PID:            %d", snap.PID)
+    } else {
+        row("PID:            —")
+    }
+    row("Время работы:   %s", snap.uptime().Round(time.Second))
+    row("Перезапуски:    %d", snap.Restarts)
+    row("Последний код:  %d", snap.LastExitCode)
+    row("%s", dashboardRule)
+    for i := 0; i < dashboardLogLines; i++ {
+        if i < len(lines) {
+            row("%s", lines[i])
+        } else {
+            row("")
+        }
+    }
+    row("%s", dashboardRule)
+    b.WriteString("Ctrl+C — остановить бота и выйти\x1b[K\x1b[J")
+    io.WriteString(d.out, b.String())
+}
+
+func dashboardState(state botState) string {
+    switch state {
+    case stateRunning:
+        return "работает"
+    case stateRestarting:
+        return "перезапуск"
+    case stateFailed:
+        return "остановлен после сбоя"
+    case stateStopped:
+        return "остановлен"
+    default:
+        return "запуск"
+    }
+}
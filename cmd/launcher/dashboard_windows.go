@@ -0,0 +1,18 @@
+package main
+
+import (
+    "os"
+
+    "golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal включает обработку ANSI-последовательностей в
+// консоли Windows 10 и новее; в старых консолях панель выводится как есть.
+func enableVirtualTerminal(f *os.File) {
+    h := windows.Handle(f.Fd())
+    var mode uint32
+    if err := windows.GetConsoleMode(h, &mode); err != nil {
+        return
+    }
+    windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "time"
+)
+
+const (
+    requirementsFileName = "requirements.txt"
+    depsSentinelName     = ".deps_installed"
+)
+
+var errPipMissing = errors.New("pip не найден")
+
+// installDeps выполняет pip install -r requirements.txt, если файл
+// зависимостей есть, а отметки об успешной установке ещё нет.
+func installDeps(pythonExe, baseDir string, env []string) error {
+    requirements := filepath.Join(baseDir, requirementsFileName)
+    if _, err := os.Stat(requirements); err != nil {
+        logger.Infof("Файл %s не найден, установка зависимостей пропущена", requirements)
+        return nil
+    }
+    sentinel := filepath.Join(baseDir, depsSentinelName)
+    if _, err := os.Stat(sentinel); err == nil {
+        return nil
+    }
+
+    check := exec.Command(pythonExe, "-m", "pip", "--version")
+    check.Env = env
+    if out, err := check.CombinedOutput(); err != nil {
+        logger.Infof("pip --version: %v: %s", err, out)
+        return errPipMissing
+    }
+
+    logger.Printf("Установка зависимостей из %s...", requirements)
+    cmd := exec.Command(pythonExe, "-m", "pip", "install", "-r", requirements)
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+    cmd.Env = env
+    if err := cmd.Run(); err != nil {
+        return fmt.Errorf("pip install: %w", err)
+    }
+
+    stamp := fmt.Sprintf("%s\n", time.Now().Format(time.RFC3339))
+    if err := os.WriteFile(sentinel, []byte(stamp), 0o644); err != nil {
+        logger.Printf("Предупреждение: не удалось записать %s: %v", sentinel, err)
+    }
+    logger.Printf("Зависимости установлены")
+    return nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+// diskFree возвращает свободное место, доступное непривилегированному
+// пользователю, на томе с каталогом dir.
+func diskFree(dir string) (uint64, error) {
+    var st syscall.Statfs_t
+    if err := syscall.Statfs(dir, &st); err != nil {
+        return 0, err
+    }
+    return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
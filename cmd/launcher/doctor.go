@@ -0,0 +1,400 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+const (
+    packageCheckTimeout = time.Minute
+    doctorMinDiskFree   = 10 << 20
+)
+
+// packageCheckScript для каждого имени из requirements.txt ищет
+// установленный дистрибутив и импортирует его модули верхнего уровня.
+// Результат печатается строками «doctor<TAB>имя<TAB>статус<TAB>подробности»,
+// чтобы вывод самих модулей при импорте не мешал разбору.
+const packageCheckScript = `import importlib, importlib.metadata as md, sys
+norm = lambda s: s.lower().replace('_', '-').replace('.', '-')
+owners = md.packages_distributions()
+for name in sys.argv[1:]:
+    try:
+        dist = md.distribution(name)
+    except md.PackageNotFoundError:
+        print('doctor', name, 'missing', '', sep='\t', flush=True)
+        continue
+    key = norm(dist.metadata['Name'])
+    mods = sorted(m for m, ds in owners.items() if not m.startswith('_') and any(norm(d) == key for d in ds))
+    err = ''
+    for m in mods:
+        try:
+            importlib.import_module(m)
+        except BaseException as e:
+            err = '%s: %s: %s' % (m, type(e).__name__, e)
+            break
+    print('doctor', name, 'error' if err else 'ok', err or dist.version, sep='\t', flush=True)`
+
+// doctorReport печатает результаты проверок --doctor и запоминает, была
+// ли среди них критическая ошибка.
+type doctorReport struct {
+    w      io.Writer
+    failed bool
+}
+
+func (r *doctorReport) section(title string) {
+    fmt.Fprintf(r.w, "\n%s\n", title)
+}
+
+func (r *doctorReport) line(mark, format string, args ...any) {
+    fmt.Fprintf(r.w, "  %-10s %s\n", mark, fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) ok(format string, args ...any) { r.line("[ok]", format, args...) }
+
+func (r *doctorReport) warn(format string, args ...any) {
+    r.line("[внимание]", format, args...)
+}
+
+func (r *doctorReport) fail(format string, args ...any) {
+    r.failed = true
+    r.line("[ошибка]", format, args...)
+}
+
+func (r *doctorReport) info(format string, args ...any) { r.line("", format, args...) }
+
+// runDoctor проверяет установку так же, как launch перед запуском бота, и
+// печатает отчёт в w. Бот не запускается, блокировка, журнал и файлы в
+// каталоге установки не трогаются. Возвращает exitFailure, если хотя бы
+// одна критическая проверка не пройдена.
+func runDoctor(opts *options, w io.Writer) int {
+    r := &doctorReport{w: w}
+    fmt.Fprintf(w, "Диагностика: %s\n", versionString())
+
+    r.section("Лаунчер")
+    exePath, err := os.Executable()
+    if err != nil {
+        r.fail("Не удалось определить путь к exe: %v", err)
+        return exitFailure
+    }
+    baseDir := filepath.Dir(exePath)
+    r.ok("Каталог лаунчера: %s", baseDir)
+    cfg, err := loadConfig(baseDir)
+    switch {
+    case err != nil:
+        r.warn("Конфигурация проигнорирована: %v", err)
+    case fileExists(filepath.Join(baseDir, configFileName)):
+        r.ok("Конфигурация: %s", filepath.Join(baseDir, configFileName))
+    default:
+        r.ok("Конфигурация: %s нет, используются значения по умолчанию", configFileName)
+    }
+
+    r.section("Бот")
+    module := cfg.Module
+    if opts.module != "" {
+        module = opts.module
+    }
+    var scriptPath string
+    if module != "" {
+        r.ok("Модуль: %s", module)
+    } else if scriptPath, err = resolveScriptPath(baseDir, opts, cfg); err != nil {
+        r.fail("Скрипт бота: %v", err)
+    } else if info, err := os.Stat(scriptPath); err != nil {
+        r.fail("Скрипт бота не найден: %s", scriptPath)
+        scriptPath = ""
+    } else {
+        r.ok("Скрипт бота: %s (%d байт)", scriptPath, info.Size())
+        if err := checkRequiredFiles(scriptPath, cfg.RequiredFiles); err != nil {
+            r.fail("Установка неполная: %v", err)
+        } else if len(cfg.RequiredFiles) > 0 {
+            r.ok("Обязательные файлы на месте: %d", len(cfg.RequiredFiles))
+        }
+    }
+
+    r.section("Интерпретатор")
+    pythonExe, venvDir := doctorInterpreter(r, baseDir, opts, cfg)
+    env := botEnvironment(baseDir, opts, cfg, venvDir)
+    if pythonExe != "" {
+        doctorPythonChecks(r, opts, cfg, pythonExe)
+        if module != "" {
+            workDir := baseDir
+            if cfg.WorkingDir != "" {
+                workDir = resolvePath(baseDir, cfg.WorkingDir)
+            }
+            if err := checkModuleImportable(pythonExe, module, workDir, env); err != nil {
+                r.fail("Модуль %s: %v", module, err)
+            } else {
+                r.ok("Модуль %s импортируется", module)
+            }
+        } else if scriptPath != "" {
+            if err := checkScriptSyntax(pythonExe, scriptPath, env); err != nil {
+                r.fail("Синтаксис скрипта: %v", err)
+            } else {
+                r.ok("Синтаксис скрипта корректен")
+            }
+        }
+
+        r.section("Зависимости")
+        doctorPackages(r, pythonExe, baseDir, env)
+    }
+
+    r.section("Диск")
+    doctorDisk(r, baseDir, cfg)
+
+    if len(cfg.Credentials) > 0 {
+        r.section("Диспетчер учётных данных")
+        if secrets, err := credentialEnv(cfg.Credentials); err != nil {
+            r.fail("%v", err)
+        } else {
+            r.ok("Получены секреты: %s", strings.Join(sortedKeys(cfg.Credentials), ", "))
+            env = append(env, secrets...)
+        }
+    }
+
+    r.section("Окружение бота")
+    final := envMap(env)
+    for _, key := range sortedKeys(final) {
+        r.info("%s=%s", key, maskEnvValue(key, final[key], cfg.Credentials))
+    }
+
+    if r.failed {
+        fmt.Fprintln(w, "\nЕсть критические ошибки, бот не запустится.")
+        return exitFailure
+    }
+    fmt.Fprintln(w, "\nКритических ошибок не найдено.")
+    return exitOK
+}
+
+// doctorInterpreter перечисляет кандидатов в том же порядке, что и launch:
+// явно заданный интерпретатор, иначе .venv, встроенный рантайм и PATH.
+func doctorInterpreter(r *doctorReport, baseDir string, opts *options, cfg *Config) (pythonExe, venvDir string) {
+    refreshPath()
+    if pinned, source := pinnedPythonPath(baseDir, opts, cfg); pinned != "" {
+        pythonExe, err := lookPinnedPython(pinned)
+        if err != nil {
+            r.fail("Интерпретатор из %s: %v", source, err)
+            return "", ""
+        }
+        r.ok("Будет использован (задан в %s): %s", source, pythonExe)
+        return pythonExe, ""
+    }
+    venv := filepath.Join(baseDir, venvDirName)
+    for _, candidate := range venvCandidates(venv) {
+        if path := doctorCandidate(r, candidate); path != "" && pythonExe == "" {
+            pythonExe, venvDir = path, venv
+        }
+    }
+    for _, candidate := range defaultCandidates(baseDir) {
+        if path := doctorCandidate(r, candidate); path != "" && pythonExe == "" {
+            pythonExe = path
+        }
+    }
+    if pythonExe == "" {
+        r.fail("Интерпретатор Python не найден")
+        return "", ""
+    }
+    r.ok("Будет использован: %s", pythonExe)
+    if venvDir != "" {
+        r.ok("Виртуальное окружение: %s", venvDir)
+    }
+    return pythonExe, venvDir
+}
+
+func doctorCandidate(r *doctorReport, candidate string) string {
+    path, err := exec.LookPath(candidate)
+    switch {
+    case errors.Is(err, fs.ErrPermission):
+        r.warn("Кандидат %s: найден, но не является исполняемым файлом", candidate)
+        return ""
+    case err != nil:
+        r.info("Кандидат %s: не найден", candidate)
+        return ""
+    }
+    if abs, err := filepath.Abs(path); err == nil {
+        path = abs
+    }
+    r.info("Кандидат %s: %s", candidate, path)
+    return path
+}
+
+func doctorPythonChecks(r *doctorReport, opts *options, cfg *Config, pythonExe string) {
+    version, err := pythonVersion(pythonExe)
+    switch {
+    case err != nil:
+        r.fail("Не удалось определить версию Python: %v", err)
+    case version.less(minPythonVersion) && opts.skipVersionCheck:
+        r.warn("Python %s старше %s (проверка отключена --skip-version-check)", version, minPythonVersion)
+    case version.less(minPythonVersion):
+        r.fail("Python %s, требуется %s или новее", version, minPythonVersion)
+    default:
+        r.ok("Версия Python: %s", version)
+    }
+
+    bits, err := pythonBits(pythonExe)
+    switch {
+    case err != nil:
+        r.warn("Не удалось определить разрядность Python: %v", err)
+    case cfg.RequiredPythonBits != 0 && bits != cfg.RequiredPythonBits:
+        r.fail("Python %d-битный, а боту нужен %d-битный", bits, cfg.RequiredPythonBits)
+    default:
+        r.ok("Разрядность Python: %d", bits)
+    }
+}
+
+// doctorPackages проверяет, что пакеты из requirements.txt установлены и
+// импортируются в выбранном интерпретаторе.
+func doctorPackages(r *doctorReport, pythonExe, baseDir string, env []string) {
+    path := filepath.Join(baseDir, requirementsFileName)
+    names, err := requirementNames(path)
+    if errors.Is(err, os.ErrNotExist) {
+        r.ok("Файл %s не найден, проверка пропущена", requirementsFileName)
+        return
+    }
+    if err != nil {
+        r.warn("Не удалось прочитать %s: %v", path, err)
+        return
+    }
+    if len(names) == 0 {
+        r.ok("В %s нет пакетов", requirementsFileName)
+        return
+    }
+
+    results, err := checkPackages(pythonExe, baseDir, env, names)
+    for _, res := range results {
+        switch res.status {
+        case "ok":
+            r.ok("%s %s", res.name, res.detail)
+        case "missing":
+            r.fail("%s не установлен", res.name)
+        default:
+            r.fail("%s не импортируется: %s", res.name, res.detail)
+        }
+    }
+    if err != nil {
+        r.fail("Проверка пакетов прервана: %v", err)
+        return
+    }
+    for _, name := range unchecked(names, results) {
+        r.warn("%s: результат проверки не получен", name)
+    }
+}
+
+// packageStatus — результат проверки одного пакета: ok (в detail версия),
+// missing или error (в detail ошибка импорта).
+type packageStatus struct {
+    name, status, detail string
+}
+
+// checkPackages запускает packageCheckScript в интерпретаторе бота с его
+// окружением. Ничего не устанавливает; ошибка означает, что проверка
+// прервалась, и результаты могут быть неполными.
+func checkPackages(pythonExe, baseDir string, env, names []string) ([]packageStatus, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), packageCheckTimeout)
+    defer cancel()
+    cmd := exec.CommandContext(ctx, pythonExe, append([]string{"-c", packageCheckScript}, names...)...)
+    cmd.Dir = baseDir
+    cmd.Env = env
+    out, err := cmd.CombinedOutput()
+    var results []packageStatus
+    for _, line := range strings.Split(string(out), "\n") {
+        fields := strings.SplitN(strings.TrimRight(line, "\r"), "\t", 4)
+        if len(fields) != 4 || fields[0] != "doctor" {
+            continue
+        }
+        results = append(results, packageStatus{name: fields[1], status: fields[2], detail: fields[3]})
+    }
+    if err != nil {
+        if msg := lastLine(out); msg != "" {
+            return results, errors.New(msg)
+        }
+        return results, err
+    }
+    return results, nil
+}
+
+func unchecked(names []string, results []packageStatus) []string {
+    checked := make(map[string]bool, len(results))
+    for _, res := range results {
+        checked[res.name] = true
+    }
+    var out []string
+    for _, name := range names {
+        if !checked[name] {
+            out = append(out, name)
+        }
+    }
+    return out
+}
+
+// requirementNames возвращает имена пакетов из requirements.txt без версий,
+// extras и маркеров окружения. Опции pip (-r, -e, --index-url), локальные
+// пути и ссылки на архивы пропускаются.
+func requirementNames(path string) ([]string, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    var names []string
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        line, _, _ := strings.Cut(scanner.Text(), "#")
+        if name := requirementName(strings.TrimPrefix(line, "\ufeff")); name != "" {
+            names = append(names, name)
+        }
+    }
+    return names, scanner.Err()
+}
+
+// requirementName выделяет имя пакета из одной строки требований, например
+// «requests>=2.31» или «pywin32; sys_platform == "win32"».
+func requirementName(line string) string {
+    line = strings.TrimSpace(line)
+    if line == "" || strings.HasPrefix(line, "-") {
+        return ""
+    }
+    if i := strings.IndexAny(line, "<>=!~;[@ \t"); i >= 0 {
+        line = line[:i]
+    }
+    if strings.ContainsAny(line, "/\\:") {
+        return ""
+    }
+    return line
+}
+
+func doctorDisk(r *doctorReport, baseDir string, cfg *Config) {
+    dir := filepath.Join(baseDir, logDirName)
+    if !fileExists(dir) {
+        dir = baseDir
+    }
+    free, err := diskFree(dir)
+    if err != nil {
+        r.warn("Не удалось определить свободное место для %s: %v", dir, err)
+        return
+    }
+    logsMaxSizeMB := defaultLogsMaxSizeMB
+    if cfg.LogsMaxSizeMB != nil {
+        logsMaxSizeMB = *cfg.LogsMaxSizeMB
+    }
+    switch {
+    case free < doctorMinDiskFree:
+        r.fail("Свободно %d МБ на диске с %s: журналы и бот могут перестать писать", free>>20, dir)
+    case free < uint64(logsMaxSizeMB)<<20:
+        r.warn("Свободно %d МБ на диске с %s, меньше предела журналов %d МБ", free>>20, dir, logsMaxSizeMB)
+    default:
+        r.ok("Свободно %d МБ на диске с %s", free>>20, dir)
+    }
+}
+
+func fileExists(path string) bool {
+    _, err := os.Stat(path)
+    return err == nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "slices"
+    "testing"
+)
+
+func TestRequirementName(t *testing.T) {
+    tests := []struct {
+        line string
+        want string
+    }{
+        {"requests", "requests"},
+        {"requests>=2.31", "requests"},
+        {"pyserial==3.5", "pyserial"},
+        {"pywin32; sys_platform == \"win32\"", "pywin32"},
+        {"uvicorn[standard]~=0.30", "uvicorn"},
+        {"pkg @ https://example/pkg.whl", "pkg"},
+        {"  Pillow !=10.0.0 ", "Pillow"},
+        {"-r other.txt", ""},
+        {"--index-url https://pypi.example/simple", ""},
+        {"-e .", ""},
+        {"./libs/local_pkg", ""},
+        {`C:\wheels\x.whl`, ""},
+        {"https://example/pkg.zip", ""},
+        {"", ""},
+    }
+    for _, tt := range tests {
+        if got := requirementName(tt.line); got != tt.want {
+            t.Errorf("requirementName(%q) = %q, ожидалось %q", tt.line, got, tt.want)
+        }
+    }
+}
+
+func TestRequirementNames(t *testing.T) {
+    path := filepath.Join(t.TempDir(), requirementsFileName)
+    content := "\ufeffrequests>=2.31  # HTTP\n# комментарий\n\n-r base.txt\npyserial\n"
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    got, err := requirementNames(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := []string{"requests", "pyserial"}; !slices.Equal(got, want) {
+        t.Errorf("requirementNames() = %q, ожидалось %q", got, want)
+    }
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+)
+
+const dotEnvFileName = ".env"
+
+type envVar struct {
+    key, value string
+}
+
+// loadDotEnv разбирает строки KEY=VALUE. Пустые строки и комментарии (#)
+// пропускаются, необязательный префикс export и кавычки вокруг значения
+// отбрасываются. Ошибочные строки возвращаются в warnings и не прерывают
+// разбор.
+func loadDotEnv(path string) (vars []envVar, warnings []error, err error) {
+    f, err := os.Open(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return nil, nil, nil
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    defer f.Close()
+
+    scanner := bufio.NewScanner(f)
+    for lineNo := 1; scanner.Scan(); lineNo++ {
+        line := strings.TrimSpace(scanner.Text())
+        if lineNo == 1 {
+            line = strings.TrimPrefix(line, "\ufeff")
+        }
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        line = strings.TrimPrefix(line, "export ")
+        key, value, ok := strings.Cut(line, "=")
+        key = strings.TrimSpace(key)
+        if !ok || key == "" || strings.ContainsAny(key, " \t\"'") {
+            warnings = append(warnings, fmt.Errorf("%s:%d: ожидается KEY=VALUE", path, lineNo))
+            continue
+        }
+        vars = append(vars, envVar{key: key, value: unquoteEnvValue(strings.TrimSpace(value))})
+    }
+    if err := scanner.Err(); err != nil {
+        return vars, warnings, err
+    }
+    return vars, warnings, nil
+}
+
+func unquoteEnvValue(value string) string {
+    if len(value) >= 2 {
+        first, last := value[0], value[len(value)-1]
+        if (first == '"' || first == '\'') && first == last {
+            return value[1 : len(value)-1]
+        }
+    }
+    return value
+}
+
+// applyDotEnv добавляет переменные из .env в env. Переменные, уже заданные
+// в окружении, сохраняются, если не указан override.
+func applyDotEnv(env []string, vars []envVar, override bool) []string {
+    for _, v := range vars {
+        if _, exists := lookupEnv(env, v.key); exists && !override {
+            continue
+        }
+        env = setEnv(env, v.key, v.value)
+    }
+    return env
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "slices"
+    "testing"
+)
+
+func TestLoadDotEnv(t *testing.T) {
+    path := filepath.Join(t.TempDir(), dotEnvFileName)
+    content := "\ufeffFIRST=1\n" +
+        "# комментарий\n" +
+        "\n" +
+        "export EXPORTED=yes\n" +
+        "  SPACED = value with spaces  \n" +
+        "DOUBLE=\"quoted # not a comment\"\n" +
+        "SINGLE='single'\n" +
+        "MISMATCHED=\"half'\n" +
+        "EMPTY=\n" +
+        "URL=postgres://u:p@host/db?x=1\n" +
+        "not a pair\n" +
+        "=novalue\n" +
+        "BAD KEY=1\n"
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    vars, warnings, err := loadDotEnv(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []envVar{
+        {"FIRST", "1"},
+        {"EXPORTED", "yes"},
+        {"SPACED", "value with spaces"},
+        {"DOUBLE", "quoted # not a comment"},
+        {"SINGLE", "single"},
+        {"MISMATCHED", "\"half'"},
+        {"EMPTY", ""},
+        {"URL", "postgres://u:p@host/db?x=1"},
+    }
+    if !slices.Equal(vars, want) {
+        t.Errorf("loadDotEnv() = %q\nожидалось      %q", vars, want)
+    }
+    if len(warnings) != 3 {
+        t.Errorf("предупреждений %d, ожидалось 3: %v", len(warnings), warnings)
+    }
+}
+
+func TestLoadDotEnvMissing(t *testing.T) {
+    vars, warnings, err := loadDotEnv(filepath.Join(t.TempDir(), dotEnvFileName))
+    if vars != nil || warnings != nil || err != nil {
+        t.Errorf("loadDotEnv() без файла = %v, %v, %v", vars, warnings, err)
+    }
+}
+
+func TestApplyDotEnv(t *testing.T) {
+    env := []string{"PATH=/usr/bin", "TOKEN=system"}
+    vars := []envVar{{"TOKEN", "dotenv"}, {"NEW", "1"}}
+    if got, want := applyDotEnv(slices.Clone(env), vars, false), []string{"PATH=/usr/bin", "TOKEN=system", "NEW=1"}; !slices.Equal(got, want) {
+        t.Errorf("applyDotEnv(override=false) = %q, ожидалось %q", got, want)
+    }
+    if got, want := applyDotEnv(slices.Clone(env), vars, true), []string{"PATH=/usr/bin", "TOKEN=dotenv", "NEW=1"}; !slices.Equal(got, want) {
+        t.Errorf("applyDotEnv(override=true) = %q, ожидалось %q", got, want)
+    }
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+)
+
+// launchPlan — то, что launch выполнил бы после всех проверок; печатается
+// в режиме --dry-run вместо запуска бота.
+type launchPlan struct {
+    pythonExe string
+    botArgs   []string
+    workDir   string
+    env       []string
+    // secretKeys — переменные из credentials, их значения не печатаются.
+    secretKeys map[string]string
+    policy     restartPolicy
+    steps      []string
+}
+
+func (p *launchPlan) print(w io.Writer) {
+    fmt.Fprintln(w, "Пробный запуск (--dry-run): бот не запускается")
+    fmt.Fprintf(w, "Интерпретатор:    %s\n", p.pythonExe)
+    fmt.Fprintf(w, "Рабочий каталог:  %s\n", p.workDir)
+    fmt.Fprintf(w, "Команда:          %s\n", formatCommandLine(p.pythonExe, p.botArgs))
+    if p.policy.window > 0 {
+        fmt.Fprintf(w, "Перезапуски:      до %d за %s, пауза %s–%s, затем %s\n", p.policy.maxRestarts, p.policy.window, p.policy.minBackoff, p.policy.maxBackoff, p.policy.action)
+    } else {
+        fmt.Fprintf(w, "Перезапуски:      до %d подряд, пауза %s–%s, затем %s\n", p.policy.maxRestarts, p.policy.minBackoff, p.policy.maxBackoff, p.policy.action)
+    }
+    if len(p.steps) > 0 {
+        fmt.Fprintln(w, "Перед запуском:")
+        for _, step := range p.steps {
+            fmt.Fprintf(w, "  %s\n", step)
+        }
+    }
+    diff := envDiff(os.Environ(), p.env, p.secretKeys)
+    fmt.Fprintf(w, "Окружение бота (отличия от окружения лаунчера, %d):\n", len(diff))
+    for _, line := range diff {
+        fmt.Fprintf(w, "  %s\n", line)
+    }
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+// relaunchElevated — повышение прав через UAC есть только в Windows,
+// в остальных системах лаунчер продолжает работу как есть.
+func relaunchElevated(args []string) (code int, relaunched bool) {
+    logger.Printf("Предупреждение: --elevate поддерживается только в Windows, запуск продолжается без повышения прав")
+    return exitOK, false
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+    "errors"
+    "os"
+    "strings"
+
+    "golang.org/x/sys/windows"
+)
+
+// relaunchElevated перезапускает лаунчер с правами администратора через
+// ShellExecute с глаголом runas, если текущий процесс их не имеет
+// (--elevate). relaunched сообщает, что запуск передан новому процессу,
+// или что пользователь отказался в окне UAC, и этот процесс должен
+// завершиться с кодом code.
+func relaunchElevated(args []string) (code int, relaunched bool) {
+    if windows.GetCurrentProcessToken().IsElevated() {
+        logger.Debugf("Лаунчер уже запущен с правами администратора")
+        return exitOK, false
+    }
+    exePath, err := os.Executable()
+    if err != nil {
+        logger.Printf("Не удалось определить путь к exe: %v", err)
+        return exitFailure, true
+    }
+    quoted := make([]string, len(args))
+    for i, a := range args {
+        quoted[i] = quoteArg(a)
+    }
+    verb, _ := windows.UTF16PtrFromString("runas")
+    file, _ := windows.UTF16PtrFromString(exePath)
+    params, _ := windows.UTF16PtrFromString(strings.Join(quoted, " "))
+    var cwd *uint16
+    if dir, err := os.Getwd(); err == nil {
+        cwd, _ = windows.UTF16PtrFromString(dir)
+    }
+    err = windows.ShellExecute(0, verb, file, params, cwd, windows.SW_SHOWNORMAL)
+    if errors.Is(err, windows.ERROR_CANCELLED) {
+        logger.Event("elevation_declined").ExitCode(exitFailure).Notify().Printf("Запуск с правами администратора отменён в окне контроля учётных записей. Бот не запущен: без этих прав он не получит доступ к фискальному устройству")
+        return exitFailure, true
+    }
+    if err != nil {
+        logger.Event("elevation_failed").ExitCode(exitFailure).Notify().Printf("Не удалось перезапустить лаунчер с правами администратора: %v", err)
+        return exitFailure, true
+    }
+    logger.Printf("Лаунчер перезапущен с правами администратора в отдельном окне")
+    return exitOK, true
+}
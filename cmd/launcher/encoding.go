@@ -0,0 +1,68 @@
+package main
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+)
+
+// pythonErrorHandlers — обработчики ошибок кодирования, которые Python
+// принимает в PYTHONIOENCODING после двоеточия.
+var pythonErrorHandlers = []string{"strict", "ignore", "replace", "backslashreplace", "surrogateescape", "xmlcharrefreplace", "namereplace"}
+
+// encodingEnv возвращает переменные кодировки и локали бота. Без настроек
+// это, как и раньше, только PYTHONUTF8=1. python_utf8: false выставляет
+// PYTHONUTF8=0, а не удаляет переменную, чтобы режим UTF-8 не включило
+// окружение лаунчера. Противоречивые настройки не отклоняются, но о них
+// возвращаются предупреждения.
+func (c *Config) encodingEnv() ([]envVar, []error) {
+    var vars []envVar
+    var warnings []error
+    utf8Mode := c.PythonUTF8 == nil || *c.PythonUTF8
+    if utf8Mode {
+        vars = append(vars, envVar{"PYTHONUTF8", "1"})
+    } else {
+        vars = append(vars, envVar{"PYTHONUTF8", "0"})
+    }
+
+    if c.PythonIOEncoding != "" {
+        encoding, handler, _ := strings.Cut(c.PythonIOEncoding, ":")
+        if handler != "" && !slices.Contains(pythonErrorHandlers, handler) {
+            warnings = append(warnings, fmt.Errorf("python_io_encoding: неизвестный обработчик ошибок %q (ожидается один из: %s)", handler, strings.Join(pythonErrorHandlers, ", ")))
+        }
+        if encoding != "" && utf8Mode && !isUTF8Encoding(encoding) {
+            warnings = append(warnings, fmt.Errorf("python_io_encoding %q меняет кодировку только stdin, stdout и stderr бота, а файлы он по-прежнему открывает в UTF-8; чтобы отключить режим UTF-8, задайте \"python_utf8\": false", c.PythonIOEncoding))
+        }
+        vars = append(vars, envVar{"PYTHONIOENCODING", c.PythonIOEncoding})
+    }
+
+    if c.Locale != "" {
+        if _, charset, ok := strings.Cut(c.Locale, "."); ok && utf8Mode && !isUTF8Encoding(strings.SplitN(charset, "@", 2)[0]) {
+            warnings = append(warnings, fmt.Errorf("locale %q задаёт кодировку %s, но в режиме UTF-8 Python её игнорирует; задайте \"python_utf8\": false", c.Locale, charset))
+        }
+        vars = append(vars, envVar{"LC_ALL", c.Locale}, envVar{"LANG", c.Locale})
+    }
+
+    // env и extra_env применяются позже и молча перебили бы эти настройки.
+    // PYTHONUTF8 по умолчанию в env переопределять можно, как и раньше.
+    for _, v := range vars {
+        if v.key == "PYTHONUTF8" && c.PythonUTF8 == nil {
+            continue
+        }
+        for _, m := range []map[string]string{c.ExtraEnv, c.Env} {
+            for key := range m {
+                if envKeyEqual(key, v.key) {
+                    warnings = append(warnings, fmt.Errorf("%s из env в %s заменяет значение, заданное настройками кодировки (%s=%s)", key, configFileName, v.key, v.value))
+                }
+            }
+        }
+    }
+    return vars, warnings
+}
+
+// isUTF8Encoding распознаёт имена UTF-8 в записи Python и Windows:
+// utf-8, UTF8, utf_8, cp65001.
+func isUTF8Encoding(name string) bool {
+    n := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+    return n == "utf8" || n == "cp65001"
+}
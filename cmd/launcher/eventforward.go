@@ -0,0 +1,109 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "regexp"
+    "strings"
+    "sync"
+)
+
+const (
+    botEventSource = "EGAISBot"
+    botEventID     = 2
+
+    eventForwardErrors = "errors"
+    eventForwardAll    = "all"
+)
+
+var logLevelPattern = regexp.MustCompile(`\b(CRITICAL|FATAL|ERROR|WARNING|WARN|INFO|DEBUG)\b`)
+
+func validEventForwardMode(mode string) error {
+    switch mode {
+    case "", eventForwardErrors, eventForwardAll:
+        return nil
+    }
+    return fmt.Errorf("неизвестный режим %q (ожидается %s или %s)", mode, eventForwardErrors, eventForwardAll)
+}
+
+// botEventLog — источник журнала событий Windows для сообщений бота.
+type botEventLog interface {
+    Info(eid uint32, msg string) error
+    Warning(eid uint32, msg string) error
+    Error(eid uint32, msg string) error
+    Close() error
+}
+
+type eventSeverity int
+
+const (
+    severityInfo eventSeverity = iota
+    severityWarning
+    severityError
+)
+
+// lineSeverity определяет уровень строки по уровню модуля logging
+// (ERROR:root:..., "2024-01-01 ... - WARNING - ...") и по трассировке
+// исключения Python.
+func lineSeverity(line string) eventSeverity {
+    if strings.HasPrefix(line, "Traceback (most recent call last)") {
+        return severityError
+    }
+    switch logLevelPattern.FindString(line) {
+    case "CRITICAL", "FATAL", "ERROR":
+        return severityError
+    case "WARNING", "WARN":
+        return severityWarning
+    }
+    return severityInfo
+}
+
+// eventForwarder построчно пересылает stderr бота в журнал событий. В
+// режиме errors пересылаются только строки уровня ERROR и CRITICAL. Ошибки
+// записи не возвращаются, чтобы не прерывать вывод бота в io.MultiWriter.
+type eventForwarder struct {
+    mu   sync.Mutex
+    log  botEventLog
+    mode string
+    line []byte
+}
+
+func (f *eventForwarder) Write(p []byte) (int, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.line = append(f.line, p...)
+    for {
+        i := bytes.IndexByte(f.line, '\n')
+        if i < 0 {
+            break
+        }
+        f.forward(strings.TrimRight(string(f.line[:i]), "\r"))
+        f.line = f.line[i+1:]
+    }
+    return len(p), nil
+}
+
+func (f *eventForwarder) forward(line string) {
+    if strings.TrimSpace(line) == "" {
+        return
+    }
+    switch severity := lineSeverity(line); {
+    case severity == severityError:
+        f.log.Error(botEventID, line)
+    case f.mode != eventForwardAll:
+    case severity == severityWarning:
+        f.log.Warning(botEventID, line)
+    default:
+        f.log.Info(botEventID, line)
+    }
+}
+
+func (f *eventForwarder) Close() error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if len(f.line) > 0 {
+        f.forward(string(f.line))
+        f.line = nil
+    }
+    return f.log.Close()
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+func runEventLogCommand(command string) int {
+    logger.Printf("Журнал событий доступен только в Windows")
+    return exitUsage
+}
+
+func openEventForwarder(mode string) *eventForwarder {
+    return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+    "errors"
+
+    "golang.org/x/sys/windows"
+    "golang.org/x/sys/windows/registry"
+    "golang.org/x/sys/windows/svc/eventlog"
+)
+
+const eventLogSourcesKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+
+// runEventLogCommand регистрирует или удаляет источник EGAISBot в журнале
+// «Приложение». Требуются права администратора.
+func runEventLogCommand(command string) int {
+    var err error
+    switch command {
+    case "install":
+        err = installBotEventSource()
+        if err == nil {
+            logger.Printf("Источник журнала событий %s зарегистрирован", botEventSource)
+        }
+    case "uninstall":
+        err = eventlog.Remove(botEventSource)
+        if err == nil {
+            logger.Printf("Источник журнала событий %s удалён", botEventSource)
+        }
+    }
+    if err != nil {
+        if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+            logger.Printf("Недостаточно прав: запустите лаунчер от имени администратора (%v)", err)
+        } else {
+            logger.Printf("Ошибка журнала событий: %v", err)
+        }
+        return exitFailure
+    }
+    return exitOK
+}
+
+func installBotEventSource() error {
+    if eventSourceRegistered(botEventSource) {
+        return nil
+    }
+    return eventlog.InstallAsEventCreate(botEventSource, eventlog.Error|eventlog.Warning|eventlog.Info)
+}
+
+func eventSourceRegistered(source string) bool {
+    k, err := registry.OpenKey(registry.LOCAL_MACHINE, eventLogSourcesKey+source, registry.QUERY_VALUE)
+    if err != nil {
+        return false
+    }
+    k.Close()
+    return true
+}
+
+// openEventForwarder возвращает пересылку stderr бота в журнал событий или
+// nil, если источник не зарегистрирован: без регистрации Windows
+// показывает вместо текста сообщение о ненайденном описании события.
+func openEventForwarder(mode string) *eventForwarder {
+    if !eventSourceRegistered(botEventSource) {
+        logger.Debugf("Источник журнала событий %s не зарегистрирован, пересылка отключена", botEventSource)
+        return nil
+    }
+    log, err := eventlog.Open(botEventSource)
+    if err != nil {
+        logger.Debugf("Не удалось открыть журнал событий: %v", err)
+        return nil
+    }
+    return &eventForwarder{log: log, mode: mode}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net"
+    "net/http"
+    "time"
+)
+
+const healthShutdownTimeout = 5 * time.Second
+
+type healthResponse struct {
+    Status        botState `json:"status"`
+    PID           int      `json:"pid"`
+    UptimeSeconds int64    `json:"uptime_seconds"`
+    Restarts      int      `json:"restarts"`
+}
+
+// startHealthServer слушает только указанный адрес и отвечает 200, пока
+// процесс бота работает, и 503 во всех остальных состояниях. Возвращаемая
+// функция останавливает сервер.
+func startHealthServer(addr string, status *botStatus) (func(), error) {
+    listener, err := net.Listen("tcp", addr)
+    if err != nil {
+        return nil, err
+    }
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+        snap := status.snapshot()
+        resp := healthResponse{
+            Status:        snap.State,
+            PID:           snap.PID,
+            UptimeSeconds: int64(snap.uptime().Seconds()),
+            Restarts:      snap.Restarts,
+        }
+        w.Header().Set("Content-Type", "application/json")
+        w.Header().Set("Cache-Control", "no-store")
+        if snap.State != stateRunning {
+            w.WriteHeader(http.StatusServiceUnavailable)
+        }
+        json.NewEncoder(w).Encode(resp)
+    })
+
+    server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+    go func() {
+        if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            logger.Printf("Сервер проверки состояния остановлен с ошибкой: %v", err)
+        }
+    }()
+
+    return func() {
+        ctx, cancel := context.WithTimeout(context.Background(), healthShutdownTimeout)
+        defer cancel()
+        server.Shutdown(ctx)
+    }, nil
+}
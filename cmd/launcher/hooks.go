@@ -0,0 +1,46 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "strconv"
+    "time"
+)
+
+const (
+    hookTimeout        = 5 * time.Minute
+    hookExitCodeEnvVar = "EGAIS_BOT_EXIT_CODE"
+)
+
+// runHook выполняет команду из launcher.json через командный интерпретатор
+// ОС (cmd /C в Windows, sh -c в остальных системах) в каталоге лаунчера и
+// пишет её вывод в журнал построчно.
+func runHook(name, command, dir string, env []string) error {
+    ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+    defer cancel()
+    cmd := shellCommand(ctx, command)
+    cmd.Dir = dir
+    cmd.Env = env
+
+    logger.Infof("Хук %s: %s", name, command)
+    out, err := cmd.CombinedOutput()
+    scanner := bufio.NewScanner(bytes.NewReader(out))
+    for scanner.Scan() {
+        logger.Infof("[%s] %s", name, scanner.Text())
+    }
+    if ctx.Err() != nil {
+        return ctx.Err()
+    }
+    return err
+}
+
+// runPostExitHook передаёт хуку код завершения бота аргументом и в
+// переменной EGAIS_BOT_EXIT_CODE.
+func runPostExitHook(command, dir string, env []string, code int) {
+    codeStr := strconv.Itoa(code)
+    env = append(env[:len(env):len(env)], hookExitCodeEnvVar+"="+codeStr)
+    if err := runHook("post_exit", command+" "+codeStr, dir, env); err != nil {
+        logger.Printf("Предупреждение: хук post_exit завершился с ошибкой: %v", err)
+    }
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "crypto/ed25519"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/base64"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+)
+
+const (
+    hashManifestName  = "manifest.sha256"
+    manifestSigPrefix = "# signature:"
+)
+
+// signingPublicKey — открытый ключ Ed25519 (32 байта в base64), которым
+// проверяются подписи, задаётся при сборке: go build -ldflags
+// "-X main.signingPublicKey=...". Закрытый ключ остаётся у конвейера
+// сборки, поэтому доступ к кассе не позволяет подделать подпись.
+var signingPublicKey = ""
+
+// publicKey разбирает signingPublicKey. Ошибка означает, что ключ не задан
+// или повреждён, и подписи проверить нельзя.
+func publicKey() (ed25519.PublicKey, error) {
+    if signingPublicKey == "" {
+        return nil, errors.New("открытый ключ подписи не задан при сборке (-X main.signingPublicKey)")
+    }
+    key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signingPublicKey))
+    if err != nil || len(key) != ed25519.PublicKeySize {
+        return nil, fmt.Errorf("открытый ключ подписи должен быть %d байтами в base64", ed25519.PublicKeySize)
+    }
+    return ed25519.PublicKey(key), nil
+}
+
+// verifySignature проверяет подпись Ed25519 (base64) сообщения msg.
+// ed25519.Verify сравнивает подпись за постоянное время.
+func verifySignature(pub ed25519.PublicKey, msg []byte, signature string) error {
+    sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+    if err != nil {
+        return fmt.Errorf("подпись: %w", err)
+    }
+    if len(sig) != ed25519.SignatureSize || !ed25519.Verify(pub, msg, sig) {
+        return errors.New("подпись неверна")
+    }
+    return nil
+}
+
+// verifyScriptHashes проверяет подписанный manifest.sha256 в папке скрипта.
+// Файл в формате sha256sum; последняя строка "# signature: <base64>"
+// содержит подпись Ed25519 всех предыдущих строк. В манифесте обязательно должен быть
+// сам скрипт; остальные перечисленные файлы тоже проверяются.
+func verifyScriptHashes(scriptPath string) error {
+    pub, err := publicKey()
+    if err != nil {
+        return err
+    }
+
+    dir := filepath.Dir(scriptPath)
+    manifestPath := filepath.Join(dir, hashManifestName)
+    data, err := os.ReadFile(manifestPath)
+    if err != nil {
+        return err
+    }
+    entries, err := parseHashManifest(data, pub)
+    if err != nil {
+        return fmt.Errorf("%s: %w", manifestPath, err)
+    }
+    logger.Debugf("Подпись %s верна, файлов в манифесте: %d", manifestPath, len(entries))
+
+    scriptName := filepath.Base(scriptPath)
+    listed := false
+    for _, e := range entries {
+        if filepath.Clean(e.path) == scriptName {
+            listed = true
+        }
+        path := filepath.Join(dir, e.path)
+        if !isInsideDir(dir, path) {
+            return fmt.Errorf("путь %s в манифесте выходит за пределы %s", e.path, dir)
+        }
+        sum, err := fileSHA256(path)
+        if err != nil {
+            return fmt.Errorf("файл %s: %w", e.path, err)
+        }
+        if subtle.ConstantTimeCompare(sum, e.sum) != 1 {
+            return fmt.Errorf("контрольная сумма файла %s не совпадает с манифестом", e.path)
+        }
+    }
+    if !listed {
+        return fmt.Errorf("скрипт %s не указан в %s", scriptName, manifestPath)
+    }
+    return nil
+}
+
+// checkRequiredFiles убеждается, что в папке скрипта есть все файлы и
+// каталоги required (пути относительно этой папки), а сам скрипт не пуст.
+// Ошибка называет первый отсутствующий путь.
+func checkRequiredFiles(scriptPath string, required []string) error {
+    info, err := os.Stat(scriptPath)
+    if err != nil {
+        return err
+    }
+    if info.Size() == 0 {
+        return fmt.Errorf("файл %s пуст", scriptPath)
+    }
+    dir := filepath.Dir(scriptPath)
+    for _, rel := range required {
+        path := filepath.Join(dir, filepath.FromSlash(rel))
+        if _, err := os.Stat(path); err != nil {
+            return fmt.Errorf("нет обязательного файла %s", path)
+        }
+    }
+    return nil
+}
+
+// syntaxCheckScript компилирует скрипт так же, как py_compile, но без
+// записи .pyc: каталог установки может быть доступен только для чтения.
+const syntaxCheckScript = `import sys
+try:
+    compile(open(sys.argv[1], 'rb').read(), sys.argv[1], 'exec')
+except SyntaxError as e:
+    sys.exit('%s: %s, строка %s' % (type(e).__name__, e.msg, e.lineno))`
+
+// checkScriptSyntax проверяет, что скрипт бота синтаксически корректен,
+// чтобы повреждённая установка не падала глубоко внутри Python.
+func checkScriptSyntax(pythonExe, scriptPath string, env []string) error {
+    ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+    defer cancel()
+    cmd := exec.CommandContext(ctx, pythonExe, "-c", syntaxCheckScript, scriptPath)
+    cmd.Env = env
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        if msg := lastLine(out); msg != "" {
+            return fmt.Errorf("%s: %s", scriptPath, msg)
+        }
+        return fmt.Errorf("%s: %w", scriptPath, err)
+    }
+    return nil
+}
+
+// checkModuleImportable проверяет, что модуль бота можно импортировать
+// из рабочего каталога бота, не выполняя его код.
+func checkModuleImportable(pythonExe, module, dir string, env []string) error {
+    ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+    defer cancel()
+    cmd := exec.CommandContext(ctx, pythonExe, "-c", "import importlib.util, sys; sys.exit(0 if importlib.util.find_spec(sys.argv[1]) else 'модуль не установлен')", module)
+    cmd.Dir = dir
+    cmd.Env = env
+    if out, err := cmd.CombinedOutput(); err != nil {
+        if msg := lastLine(out); msg != "" {
+            return errors.New(msg)
+        }
+        return err
+    }
+    return nil
+}
+
+func lastLine(out []byte) string {
+    lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+    return strings.TrimSpace(lines[len(lines)-1])
+}
+
+type hashEntry struct {
+    sum  []byte
+    path string
+}
+
+func parseHashManifest(data []byte, pub ed25519.PublicKey) ([]hashEntry, error) {
+    trimmed := bytes.TrimRight(data, "\r\n")
+    cut := bytes.LastIndexByte(trimmed, '\n') + 1
+    sigLine := strings.TrimSpace(string(trimmed[cut:]))
+    if !strings.HasPrefix(sigLine, manifestSigPrefix) {
+        return nil, errors.New("последняя строка должна содержать подпись \"" + manifestSigPrefix + " <base64>\"")
+    }
+    body := data[:cut]
+    if err := verifySignature(pub, body, strings.TrimPrefix(sigLine, manifestSigPrefix)); err != nil {
+        return nil, fmt.Errorf("подпись манифеста: %w", err)
+    }
+
+    var entries []hashEntry
+    scanner := bufio.NewScanner(bytes.NewReader(body))
+    for lineNo := 1; scanner.Scan(); lineNo++ {
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        sumHex, path, ok := strings.Cut(line, " ")
+        path = strings.TrimPrefix(strings.TrimSpace(path), "*")
+        sum, err := hex.DecodeString(sumHex)
+        if !ok || err != nil || len(sum) != sha256.Size || path == "" {
+            return nil, fmt.Errorf("строка %d: ожидается \"<sha256> <путь>\"", lineNo)
+        }
+        entries = append(entries, hashEntry{sum: sum, path: filepath.FromSlash(path)})
+    }
+    return entries, scanner.Err()
+}
+
+func fileSHA256(path string) ([]byte, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    h := sha256.New()
+    if _, err := io.Copy(h, f); err != nil {
+        return nil, err
+    }
+    return h.Sum(nil), nil
+}
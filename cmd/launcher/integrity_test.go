@@ -0,0 +1,86 @@
+package main
+
+import (
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// signHashManifest собирает manifest.sha256 так же, как конвейер сборки:
+// строки sha256sum и подпись Ed25519 последней строкой.
+func signHashManifest(t *testing.T, dir string, priv ed25519.PrivateKey, names ...string) {
+    t.Helper()
+    var body []byte
+    for _, name := range names {
+        data, err := os.ReadFile(filepath.Join(dir, name))
+        if err != nil {
+            t.Fatal(err)
+        }
+        sum := sha256.Sum256(data)
+        body = append(body, hex.EncodeToString(sum[:])+"  "+name+"\n"...)
+    }
+    sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, body))
+    body = append(body, manifestSigPrefix+" "+sig+"\n"...)
+    if err := os.WriteFile(filepath.Join(dir, hashManifestName), body, 0o644); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestVerifyScriptHashes(t *testing.T) {
+    pub, priv, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    oldKey := signingPublicKey
+    t.Cleanup(func() { signingPublicKey = oldKey })
+
+    tests := []struct {
+        name    string
+        prepare func(t *testing.T, dir string)
+        noKey   bool
+        wantErr bool
+    }{
+        {"подпись и файлы верны", func(t *testing.T, dir string) {
+            signHashManifest(t, dir, priv, "main.py", "util.py")
+        }, false, false},
+        {"изменён файл", func(t *testing.T, dir string) {
+            signHashManifest(t, dir, priv, "main.py", "util.py")
+            os.WriteFile(filepath.Join(dir, "util.py"), []byte("import os\n"), 0o644)
+        }, false, true},
+        {"манифест переподписан чужим ключом", func(t *testing.T, dir string) {
+            os.WriteFile(filepath.Join(dir, "main.py"), []byte("print('evil')\n"), 0o644)
+            signHashManifest(t, dir, otherPriv, "main.py")
+        }, false, true},
+        {"скрипта нет в манифесте", func(t *testing.T, dir string) {
+            signHashManifest(t, dir, priv, "util.py")
+        }, false, true},
+        {"ключ не задан при сборке", func(t *testing.T, dir string) {
+            signHashManifest(t, dir, priv, "main.py")
+        }, true, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            dir := t.TempDir()
+            os.WriteFile(filepath.Join(dir, "main.py"), []byte("print('ok')\n"), 0o644)
+            os.WriteFile(filepath.Join(dir, "util.py"), []byte("X = 1\n"), 0o644)
+            tt.prepare(t, dir)
+            signingPublicKey = base64.StdEncoding.EncodeToString(pub)
+            if tt.noKey {
+                signingPublicKey = ""
+            }
+            err := verifyScriptHashes(filepath.Join(dir, "main.py"))
+            if (err != nil) != tt.wantErr {
+                t.Errorf("verifyScriptHashes() = %v, ошибка ожидалась: %v", err, tt.wantErr)
+            }
+        })
+    }
+}
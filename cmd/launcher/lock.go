@@ -0,0 +1,198 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    lockFileName = "launcher.lock"
+    // Файл блокировки без PID моложе этого возраста считаем ещё
+    // записываемым другим экземпляром, а не брошенным.
+    lockWriteGrace = 5 * time.Second
+    // takeoverGrace добавляется к времени остановки бота: старому лаунчеру
+    // нужно ещё освободить блокировку и завершиться.
+    takeoverGrace = 5 * time.Second
+)
+
+var errLockHeld = errors.New("лаунчер уже запущен")
+
+type instanceLock struct {
+    path string
+}
+
+type lockHeldError struct {
+    pid int
+}
+
+func (e *lockHeldError) Error() string {
+    if e.pid > 0 {
+        return fmt.Sprintf("%v (PID %d)", errLockHeld, e.pid)
+    }
+    return errLockHeld.Error()
+}
+
+func (e *lockHeldError) Unwrap() error {
+    return errLockHeld
+}
+
+func acquireLock(baseDir string) (*instanceLock, error) {
+    path := filepath.Join(baseDir, lockFileName)
+    for attempt := 0; attempt < 2; attempt++ {
+        f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+        if err == nil {
+            _, werr := fmt.Fprintf(f, "%d\n", os.Getpid())
+            cerr := f.Close()
+            if werr == nil {
+                werr = cerr
+            }
+            if werr != nil {
+                os.Remove(path)
+                return nil, werr
+            }
+            return &instanceLock{path: path}, nil
+        }
+        if !errors.Is(err, os.ErrExist) {
+            return nil, err
+        }
+
+        pid, stale := inspectLock(path)
+        if !stale {
+            return nil, &lockHeldError{pid: pid}
+        }
+        logger.Printf("Найдена устаревшая блокировка %s (PID %d), она будет снята", path, pid)
+        if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return nil, err
+        }
+    }
+    return nil, &lockHeldError{}
+}
+
+// inspectLock читает PID из файла блокировки и сообщает, можно ли
+// считать блокировку брошенной.
+func inspectLock(path string) (pid int, stale bool) {
+    data, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return 0, true
+    }
+    if err != nil {
+        return 0, false
+    }
+    pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+    if err != nil || pid <= 0 {
+        info, statErr := os.Stat(path)
+        if statErr != nil {
+            return 0, errors.Is(statErr, os.ErrNotExist)
+        }
+        return 0, time.Since(info.ModTime()) > lockWriteGrace
+    }
+    return pid, !processAlive(pid)
+}
+
+func (l *instanceLock) release() {
+    if l == nil {
+        return
+    }
+    if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+        logger.Printf("Не удалось удалить файл блокировки %s: %v", l.path, err)
+    }
+}
+
+// takeoverLock забирает блокировку у работающего экземпляра (--takeover):
+// просит его остановиться, ждёт до timeout, затем завершает принудительно
+// и берёт блокировку. Оставшийся бот затем останавливает terminateOrphan.
+// Файл launcher.takeover создаётся эксклюзивно, поэтому из двух
+// одновременных перехватов продолжает только один.
+func takeoverLock(baseDir, exePath string, timeout time.Duration) (*instanceLock, error) {
+    marker, err := acquireTakeoverMarker(baseDir)
+    if err != nil {
+        return nil, err
+    }
+    defer marker.release()
+
+    path := filepath.Join(baseDir, lockFileName)
+    pid, stale := inspectLock(path)
+    if !stale && pid > 0 {
+        cmdline, err := processCommandLine(pid)
+        switch {
+        case err != nil:
+            return nil, fmt.Errorf("не удалось проверить процесс PID %d: %w", pid, err)
+        case !commandLineMentions(cmdline, filepath.Base(exePath)):
+            // PID из блокировки занят посторонним процессом: лаунчер,
+            // записавший его, давно завершился.
+            logger.Printf("PID %d из %s принадлежит другому процессу (%s), блокировка будет снята", pid, path, cmdline)
+        default:
+            stopLauncher(pid, timeout)
+        }
+        if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return nil, err
+        }
+    }
+    return acquireLock(baseDir)
+}
+
+func stopLauncher(pid int, timeout time.Duration) {
+    logger.Event("takeover").Printf("Лаунчер PID %d удерживает блокировку и будет остановлен (--takeover)", pid)
+    if err := requestLauncherStop(pid); err != nil {
+        logger.Printf("Не удалось попросить лаунчер PID %d остановиться: %v", pid, err)
+    } else {
+        deadline := time.Now().Add(timeout)
+        for time.Now().Before(deadline) {
+            if !processAlive(pid) {
+                logger.Printf("Лаунчер PID %d завершился", pid)
+                return
+            }
+            time.Sleep(orphanPollInterval)
+        }
+        logger.Printf("Лаунчер PID %d не завершился за %s", pid, timeout)
+    }
+    p, err := os.FindProcess(pid)
+    if err != nil {
+        logger.Printf("Не удалось открыть процесс PID %d: %v", pid, err)
+        return
+    }
+    defer p.Release()
+    if err := p.Kill(); err != nil && processAlive(pid) {
+        logger.Printf("Не удалось завершить лаунчер PID %d: %v", pid, err)
+        return
+    }
+    logger.Printf("Лаунчер PID %d завершён принудительно", pid)
+}
+
+const takeoverFileName = "launcher.takeover"
+
+// acquireTakeoverMarker создаёт launcher.takeover с нашим PID. Файл,
+// оставшийся от завершившегося процесса, снимается.
+func acquireTakeoverMarker(baseDir string) (*instanceLock, error) {
+    path := filepath.Join(baseDir, takeoverFileName)
+    for attempt := 0; attempt < 2; attempt++ {
+        f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+        if err == nil {
+            _, werr := fmt.Fprintf(f, "%d\n", os.Getpid())
+            if cerr := f.Close(); werr == nil {
+                werr = cerr
+            }
+            if werr != nil {
+                os.Remove(path)
+                return nil, werr
+            }
+            return &instanceLock{path: path}, nil
+        }
+        if !errors.Is(err, os.ErrExist) {
+            return nil, err
+        }
+        pid, stale := inspectLock(path)
+        if !stale {
+            return nil, fmt.Errorf("%w: блокировку уже перехватывает PID %d", errLockHeld, pid)
+        }
+        if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return nil, err
+        }
+    }
+    return nil, fmt.Errorf("%w: не удалось создать %s", errLockHeld, path)
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    logDirName         = "logs"
+    launcherLogName    = "launcher.log"
+    logMaxSize         = 5 << 20
+    logMaxBackups      = 5
+    logTimestampLayout = "2006-01-02 15:04:05.000"
+)
+
+// rotatingFile пишет в файл и при превышении maxSize сдвигает его
+// в name.1, name.2 … name.N, удаляя самый старый.
+type rotatingFile struct {
+    mu         sync.Mutex
+    path       string
+    maxSize    int64
+    maxBackups int
+    file       *os.File
+    size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return nil, err
+    }
+    r := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+    if err := r.open(); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+func (r *rotatingFile) open() error {
+    f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+    if err != nil {
+        return err
+    }
+    info, err := f.Stat()
+    if err != nil {
+        f.Close()
+        return err
+    }
+    r.file = f
+    r.size = info.Size()
+    return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.file == nil {
+        return 0, os.ErrClosed
+    }
+    if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+        if err := r.rotate(); err != nil {
+            return 0, err
+        }
+    }
+    n, err := r.file.Write(p)
+    r.size += int64(n)
+    return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+    if err := r.file.Close(); err != nil {
+        return err
+    }
+    r.file = nil
+    os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxBackups))
+    for i := r.maxBackups - 1; i >= 1; i-- {
+        os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+    }
+    if r.maxBackups > 0 {
+        os.Rename(r.path, r.path+".1")
+    } else {
+        os.Remove(r.path)
+    }
+    return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.file == nil {
+        return nil
+    }
+    err := r.file.Close()
+    r.file = nil
+    return err
+}
+
+// eventSink — журнал событий Windows, в который пишет служба.
+type eventSink interface {
+    Info(eid uint32, msg string) error
+    Warning(eid uint32, msg string) error
+    Error(eid uint32, msg string) error
+}
+
+type logFormat string
+
+const (
+    logFormatText logFormat = "text"
+    logFormatJSON logFormat = "json"
+)
+
+// logEntry — одна запись журнала. В режиме json она выводится целиком
+// одной строкой, в текстовом режиме — только время и message.
+type logEntry struct {
+    Event        string `json:"event"`
+    Timestamp    string `json:"timestamp"`
+    Interpreter  string `json:"interpreter,omitempty"`
+    ExitCode     *int   `json:"exit_code,omitempty"`
+    RestartCount *int   `json:"restart_count,omitempty"`
+    Message      string `json:"message"`
+}
+
+// launcherLog дублирует сообщения лаунчера в консоль и в файл журнала.
+// Infof пишет только в файл, чтобы не менять вывод интерактивного запуска;
+// в режиме json в консоль попадают все записи.
+type launcherLog struct {
+    mu      sync.Mutex
+    console io.Writer
+    file    io.WriteCloser
+    events  eventSink
+    format  logFormat
+    verbose bool
+    // quiet отключает уведомления Notify, например в режиме --dry-run.
+    quiet bool
+}
+
+var logger = &launcherLog{console: os.Stderr, format: logFormatText}
+
+func (l *launcherLog) setFormat(format logFormat) {
+    l.mu.Lock()
+    l.format = format
+    l.mu.Unlock()
+}
+
+func (l *launcherLog) setVerbose(verbose bool) {
+    l.mu.Lock()
+    l.verbose = verbose
+    l.mu.Unlock()
+}
+
+func (l *launcherLog) setQuiet(quiet bool) {
+    l.mu.Lock()
+    l.quiet = quiet
+    l.mu.Unlock()
+}
+
+func (l *launcherLog) openFile(baseDir string) error {
+    f, err := openRotatingFile(filepath.Join(baseDir, logDirName, launcherLogName), logMaxSize, logMaxBackups)
+    if err != nil {
+        return err
+    }
+    l.mu.Lock()
+    l.file = f
+    l.mu.Unlock()
+    return nil
+}
+
+func (l *launcherLog) setConsole(w io.Writer) {
+    l.mu.Lock()
+    l.console = w
+    l.mu.Unlock()
+}
+
+func (l *launcherLog) setEventSink(events eventSink) {
+    l.mu.Lock()
+    l.events = events
+    l.mu.Unlock()
+}
+
+func (l *launcherLog) Close() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.file != nil {
+        l.file.Close()
+        l.file = nil
+    }
+}
+
+// Event начинает запись с именем события для структурированного журнала.
+func (l *launcherLog) Event(name string) *logEvent {
+    return &logEvent{log: l, entry: logEntry{Event: name}}
+}
+
+func (l *launcherLog) Printf(format string, args ...any) {
+    l.Event("message").Printf(format, args...)
+}
+
+func (l *launcherLog) Infof(format string, args ...any) {
+    l.Event("info").Infof(format, args...)
+}
+
+// Debugf пишет в консоль и в файл только в режиме --verbose.
+func (l *launcherLog) Debugf(format string, args ...any) {
+    l.mu.Lock()
+    verbose := l.verbose
+    l.mu.Unlock()
+    if verbose {
+        l.Event("debug").Printf(format, args...)
+    }
+}
+
+type logEvent struct {
+    log    *launcherLog
+    entry  logEntry
+    notify bool
+}
+
+func (e *logEvent) Interpreter(path string) *logEvent {
+    e.entry.Interpreter = path
+    return e
+}
+
+func (e *logEvent) ExitCode(code int) *logEvent {
+    e.entry.ExitCode = &code
+    return e
+}
+
+func (e *logEvent) Restarts(n int) *logEvent {
+    e.entry.RestartCount = &n
+    return e
+}
+
+// Notify дополнительно показывает сообщение пользователю уведомлением
+// Windows: консоль лаунчера у кассира обычно закрывается раньше, чем он
+// успевает прочитать ошибку.
+func (e *logEvent) Notify() *logEvent {
+    e.notify = true
+    return e
+}
+
+// Printf пишет событие в консоль и в файл.
+func (e *logEvent) Printf(format string, args ...any) {
+    e.entry.Message = fmt.Sprintf(format, args...)
+    e.log.write(true, e.notify, e.entry)
+    e.log.mu.Lock()
+    quiet := e.log.quiet
+    e.log.mu.Unlock()
+    if e.notify && !quiet {
+        showFailureNotification(e.entry.Message)
+    }
+}
+
+// Infof пишет событие только в файл (и в консоль в режиме json).
+func (e *logEvent) Infof(format string, args ...any) {
+    e.entry.Message = fmt.Sprintf(format, args...)
+    e.log.write(false, false, e.entry)
+}
+
+func (l *launcherLog) write(toConsole, notify bool, entry logEntry) {
+    now := time.Now()
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.format == logFormatJSON {
+        entry.Timestamp = now.Format(time.RFC3339Nano)
+        line, _ := json.Marshal(entry)
+        if l.console != nil {
+            fmt.Fprintf(l.console, "%s\n", line)
+        }
+        if l.file != nil {
+            fmt.Fprintf(l.file, "%s\n", line)
+        }
+    } else {
+        if toConsole && l.console != nil {
+            fmt.Fprintln(l.console, entry.Message)
+        }
+        if l.file != nil {
+            fmt.Fprintf(l.file, "%s %s\n", now.Format(logTimestampLayout), entry.Message)
+        }
+    }
+    if l.events != nil {
+        switch eventLevel(notify, entry) {
+        case "error":
+            l.events.Error(1, entry.Message)
+        case "warning":
+            l.events.Warning(1, entry.Message)
+        default:
+            l.events.Info(1, entry.Message)
+        }
+    }
+}
+
+// eventLevel — уровень записи в журнале событий Windows. Ошибки — только
+// события сбоя (с уведомлением или ненулевым кодом выхода), предупреждения —
+// сообщения «Предупреждение: …», остальное, в том числе «Запуск бота» и
+// «Бот остановлен», — сведения, чтобы не засорять журнал «Приложение».
+// bot_stopped — остановка по запросу: код выхода прерванного бота обычно
+// ненулевой, но сбоем это не является.
+func eventLevel(notify bool, entry logEntry) string {
+    switch {
+    case notify || entry.ExitCode != nil && *entry.ExitCode != 0 && entry.Event != "bot_stopped":
+        return "error"
+    case strings.HasPrefix(entry.Message, "Предупреждение"):
+        return "warning"
+    default:
+        return "info"
+    }
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+// fakeEventSink запоминает уровни записей, отправленных в журнал событий.
+type fakeEventSink struct {
+    levels []string
+}
+
+func (f *fakeEventSink) Info(uint32, string) error {
+    f.levels = append(f.levels, "info")
+    return nil
+}
+
+func (f *fakeEventSink) Warning(uint32, string) error {
+    f.levels = append(f.levels, "warning")
+    return nil
+}
+
+func (f *fakeEventSink) Error(uint32, string) error {
+    f.levels = append(f.levels, "error")
+    return nil
+}
+
+func TestEventLogLevels(t *testing.T) {
+    tests := []struct {
+        name string
+        log  func(l *launcherLog)
+        want string
+    }{
+        {"Infof", func(l *launcherLog) { l.Event("bot_start").Infof("Запуск бота") }, "info"},
+        {"обычный Printf", func(l *launcherLog) { l.Printf("Бот работает") }, "info"},
+        {"остановка по запросу", func(l *launcherLog) {
+            l.Event("bot_stopped").ExitCode(1).Printf("Бот остановлен (код 1)")
+        }, "info"},
+        {"предупреждение", func(l *launcherLog) {
+            l.Printf("Предупреждение: конфигурация проигнорирована")
+        }, "warning"},
+        {"ненулевой код", func(l *launcherLog) {
+            l.Event("bot_exit").ExitCode(2).Printf("Python скрипт завершился с кодом 2")
+        }, "error"},
+        {"нулевой код", func(l *launcherLog) { l.Event("bot_exit").ExitCode(0).Printf("Бот завершился") }, "info"},
+        {"уведомление", func(l *launcherLog) { l.Event("no_interpreter").Notify().Printf("Python не найден") }, "error"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            sink := &fakeEventSink{}
+            // quiet: уведомление не должно запускать PowerShell во время теста.
+            l := &launcherLog{format: logFormatText, events: sink, quiet: true}
+            tt.log(l)
+            if len(sink.levels) != 1 || sink.levels[0] != tt.want {
+                t.Errorf("уровни в журнале событий %v, ожидался %s", sink.levels, tt.want)
+            }
+        })
+    }
+}
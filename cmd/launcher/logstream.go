@@ -0,0 +1,175 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "net"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    logStreamReplayLines  = 500
+    logStreamClientBuffer = 1024
+    logStreamWriteTimeout = 10 * time.Second
+)
+
+// logStream раздаёт строки вывода бота подключившимся TCP-клиентам,
+// например `ncat 127.0.0.1 9999`. Новому клиенту сначала отправляются
+// последние строки из кольцевого буфера. Запись в поток никогда не ждёт
+// клиентов: если клиент не успевает читать, строки для него пропускаются.
+type logStream struct {
+    listener net.Listener
+    history  *stderrTail
+
+    mu      sync.Mutex
+    clients map[*logStreamClient]struct{}
+    closed  bool
+}
+
+type logStreamClient struct {
+    conn    net.Conn
+    lines   chan string
+    dropped int
+}
+
+// startLogStream слушает только указанный адрес: подключиться может любой,
+// кто до него дотянется, поэтому по умолчанию стоит указывать 127.0.0.1.
+func startLogStream(addr string) (*logStream, error) {
+    listener, err := net.Listen("tcp", addr)
+    if err != nil {
+        return nil, err
+    }
+    if tcp, ok := listener.Addr().(*net.TCPAddr); ok && !tcp.IP.IsLoopback() {
+        logger.Printf("Предупреждение: поток журнала на %s доступен с других компьютеров без пароля", listener.Addr())
+    }
+    s := &logStream{
+        listener: listener,
+        history:  newStderrTail(logStreamReplayLines),
+        clients:  make(map[*logStreamClient]struct{}),
+    }
+    go s.accept()
+    return s, nil
+}
+
+func (s *logStream) accept() {
+    for {
+        conn, err := s.listener.Accept()
+        if err != nil {
+            return
+        }
+        c := &logStreamClient{conn: conn, lines: make(chan string, logStreamClientBuffer)}
+        // История и регистрация клиента под одной блокировкой, чтобы строки
+        // не потерялись и не повторились между ними.
+        s.mu.Lock()
+        if s.closed {
+            s.mu.Unlock()
+            conn.Close()
+            return
+        }
+        replay := s.history.snapshot()
+        s.clients[c] = struct{}{}
+        s.mu.Unlock()
+        logger.Infof("Подключился клиент потока журнала %s", conn.RemoteAddr())
+        go s.serve(c, replay)
+    }
+}
+
+func (s *logStream) serve(c *logStreamClient, replay []string) {
+    defer s.drop(c)
+    for _, line := range replay {
+        if !c.send(line) {
+            return
+        }
+    }
+    for line := range c.lines {
+        s.mu.Lock()
+        dropped := c.dropped
+        c.dropped = 0
+        s.mu.Unlock()
+        if dropped > 0 && !c.send(fmt.Sprintf("... пропущено строк: %d", dropped)) {
+            return
+        }
+        if !c.send(line) {
+            return
+        }
+    }
+}
+
+func (c *logStreamClient) send(line string) bool {
+    c.conn.SetWriteDeadline(time.Now().Add(logStreamWriteTimeout))
+    _, err := io.WriteString(c.conn, line+"\n")
+    return err == nil
+}
+
+func (s *logStream) drop(c *logStreamClient) {
+    s.mu.Lock()
+    _, ok := s.clients[c]
+    delete(s.clients, c)
+    s.mu.Unlock()
+    c.conn.Close()
+    if ok {
+        logger.Infof("Клиент потока журнала %s отключился", c.conn.RemoteAddr())
+    }
+}
+
+func (s *logStream) publish(line string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.closed {
+        return
+    }
+    s.history.Write([]byte(line + "\n"))
+    for c := range s.clients {
+        select {
+        case c.lines <- line:
+        default:
+            c.dropped++
+        }
+    }
+}
+
+// writer возвращает приёмник для io.MultiWriter, который помечает строки
+// именем потока. Ошибки не возвращаются, чтобы не прерывать вывод бота.
+func (s *logStream) writer(name string) io.Writer {
+    return &logStreamWriter{stream: s, prefix: "[" + name + "] "}
+}
+
+func (s *logStream) Close() {
+    s.mu.Lock()
+    if s.closed {
+        s.mu.Unlock()
+        return
+    }
+    s.closed = true
+    clients := s.clients
+    s.clients = nil
+    s.mu.Unlock()
+    s.listener.Close()
+    for c := range clients {
+        close(c.lines)
+    }
+}
+
+type logStreamWriter struct {
+    mu     sync.Mutex
+    stream *logStream
+    prefix string
+    line   []byte
+}
+
+func (w *logStreamWriter) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    for _, chunk := range bytes.SplitAfter(p, []byte{'\n'}) {
+        w.line = append(w.line, chunk...)
+        if bytes.HasSuffix(chunk, []byte{'\n'}) || len(w.line) >= maxTailLineLen {
+            text := strings.TrimRight(string(w.line), "\r\n")
+            w.stream.publish(time.Now().Format(logTimestampLayout) + " " + w.prefix + text)
+            w.line = w.line[:0]
+        }
+    }
+    return len(p), nil
+}
@@ -1,53 +1,587 @@
 package main
 
 import (
+    "errors"
+    "flag"
     "fmt"
+    "io"
     "os"
     "os/exec"
+    "os/signal"
     "path/filepath"
+    "runtime"
+    "strings"
+    "syscall"
+    "time"
 )
 
+// Коды выхода лаунчера. Сценарии развёртывания различают по ним причину
+// отказа, поэтому значения перечислены в --help и README и не должны
+// меняться. exitUsage — ошибка в самой команде запуска, в том числе
+// параметр, доступный только в Windows; причины отказа при подготовке
+// бота занимают 10–19. Прочие коды — код завершения самого бота.
+const (
+    exitOK             = 0
+    exitFailure        = 1
+    exitUsage          = 4
+    exitNoInterpreter  = 10
+    exitScriptNotFound = 11
+    exitLockHeld       = 12
+    exitVersionTooOld  = 13
+    exitDepsFailed     = 14
+    exitIntegrity      = 15
+    exitNotReady       = 16
+    exitHookFailed     = 17
+    exitSecretMissing  = 18
+    exitArchMismatch   = 19
+)
+
+var defaultScriptRel = filepath.Join(defaultAppName, appScriptName)
+
+// version задаётся при сборке: go build -ldflags "-X main.version=1.2.3"
+var version = "dev"
+
+func versionString() string {
+    return fmt.Sprintf("launcher %s (%s, %s/%s)", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
 func main() {
+    os.Exit(run())
+}
+
+func run() int {
+    opts, err := parseArgs(os.Args[1:], os.Stderr)
+    if err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return exitOK
+        }
+        return exitUsage
+    }
+
+    logger.setFormat(opts.logFormat)
+    logger.setVerbose(opts.verbose)
+
+    if opts.showVersion {
+        fmt.Fprintln(os.Stdout, versionString())
+        return exitOK
+    }
+
+    if opts.doctor {
+        return runDoctor(opts, os.Stdout)
+    }
+    if opts.checkDeps {
+        return runCheckDeps(opts, os.Stdout)
+    }
+    if opts.dryRun {
+        logger.setQuiet(true)
+        return launch(opts, newController(nil))
+    }
+
+    if opts.cleanup {
+        return runCleanup(opts.cleanupLogs, os.Stdout)
+    }
+
+    if opts.eventLog != "" {
+        return runEventLogCommand(opts.eventLog)
+    }
+
+    if opts.install || opts.uninstall {
+        args := stripFlag(stripBoolFlag(stripBoolFlag(os.Args[1:], "install"), "uninstall"), "shortcuts")
+        locations := opts.shortcuts
+        if opts.uninstall {
+            locations = "desktop,start-menu"
+        }
+        return runShortcutCommand(opts.install, locations, args)
+    }
+    if opts.registerStartup || opts.unregisterStartup {
+        return runStartupCommand(opts.registerStartup, stripBoolFlag(stripBoolFlag(os.Args[1:], "register-startup"), "unregister-startup"))
+    }
+
+    if opts.service != "" {
+        return runServiceCommand(opts, stripFlag(os.Args[1:], "service"))
+    }
+
+    if opts.elevate {
+        if code, relaunched := relaunchElevated(stripBoolFlag(os.Args[1:], "elevate")); relaunched {
+            return code
+        }
+    }
+
+    signals := make(chan os.Signal, 1)
+    signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+    defer signal.Stop(signals)
+    watchStopRequests(signals)
+    ctl := newController(signals)
+    if opts.tray {
+        return runTray(opts, ctl)
+    }
+    if opts.dashboard {
+        return runDashboard(opts, ctl)
+    }
+    return launch(opts, ctl)
+}
+
+// launch выполняет полный цикл запуска бота: поиск скрипта и интерпретатора,
+// проверки окружения и наблюдение за процессом до его завершения или
+// прихода сигнала остановки через ctl.
+func launch(opts *options, ctl *controller) int {
     exePath, err := os.Executable()
     if err != nil {
-        fmt.Fprintf(os.Stderr, "Не удалось определить путь к exe: %v\n", err)
-        return
+        logger.Printf("Не удалось определить путь к exe: %v", err)
+        return exitFailure
     }
+    launcherStarted := time.Now()
     baseDir := filepath.Dir(exePath)
-    scriptPath := filepath.Join(baseDir, "bot_app", "main.py")
-    if _, err := os.Stat(scriptPath); err != nil {
-        fmt.Fprintf(os.Stderr, "Не найден скрипт бота: %s\n", scriptPath)
-        return
+
+    // Пробный запуск ничего не меняет в каталоге установки: не пишет
+    // журнал, не берёт блокировку (бот может работать) и не применяет
+    // обновления.
+    if !opts.dryRun {
+        if err := logger.openFile(baseDir); err != nil {
+            logger.Printf("Предупреждение: не удалось открыть журнал лаунчера: %v", err)
+        }
+        defer logger.Close()
+        logger.Event("launcher_start").Infof("Лаунчер запущен: %s, %s", exePath, versionString())
+
+        lock, err := acquireLock(baseDir)
+        if errors.Is(err, errLockHeld) && opts.takeover {
+            timeout := defaultShutdownTimeout
+            if opts.shutdownTimeout > 0 {
+                timeout = opts.shutdownTimeout
+            }
+            lock, err = takeoverLock(baseDir, exePath, timeout+takeoverGrace)
+        }
+        if err != nil {
+            if errors.Is(err, errLockHeld) {
+                logger.Event("lock_held").ExitCode(exitLockHeld).Printf("Бот уже запущен другим экземпляром лаунчера (%v). Закройте его перед повторным запуском.", err)
+                return exitLockHeld
+            }
+            logger.Printf("Не удалось создать файл блокировки: %v", err)
+            return exitFailure
+        }
+        defer lock.release()
+
+        applyStagedUpdate(baseDir)
+    }
+
+    cfg, err := loadConfig(baseDir)
+    if err != nil {
+        logger.Printf("Предупреждение: %s проигнорирован, используются настройки из реестра или значения по умолчанию: %v", configFileName, err)
     }
 
-    candidates := []string{
-        filepath.Join(baseDir, "python", "pythonw.exe"),
-        filepath.Join(baseDir, "python", "python.exe"),
-        "pythonw",
-        "python",
+    retentionDays, logsMaxSizeMB := defaultLogRetentionDays, defaultLogsMaxSizeMB
+    if cfg.LogRetentionDays != nil {
+        retentionDays = *cfg.LogRetentionDays
+    }
+    if cfg.LogsMaxSizeMB != nil {
+        logsMaxSizeMB = *cfg.LogsMaxSizeMB
+    }
+    if !opts.dryRun {
+        cleanupLogs(baseDir, time.Duration(retentionDays)*24*time.Hour, int64(logsMaxSizeMB)<<20)
     }
 
-    var pythonExe string
-    for _, candidate := range candidates {
-        if path, err := exec.LookPath(candidate); err == nil {
-            pythonExe = path
-            break
+    updateURL := cfg.UpdateManifestURL
+    if opts.noUpdate || opts.dryRun {
+        updateURL = ""
+    }
+    if updateURL != "" {
+        go checkForUpdate(baseDir, updateURL)
+    }
+
+    module := cfg.Module
+    if opts.module != "" {
+        module = opts.module
+    }
+    var scriptPath string
+    if module != "" {
+        if opts.script != "" || opts.app != "" {
+            logger.Printf("Предупреждение: задан модуль %s, параметры --script и --app игнорируются", module)
+        }
+        if opts.verifyHash || cfg.VerifyHash {
+            logger.Printf("Предупреждение: --verify-hash не применяется к запуску модуля")
+        }
+    } else {
+        scriptPath, err = resolveScriptPath(baseDir, opts, cfg)
+        if errors.Is(err, errUnknownApp) {
+            logger.Event("script_not_found").ExitCode(exitScriptNotFound).Notify().Printf("Не найден скрипт бота: %v", err)
+            return exitScriptNotFound
+        }
+        if err != nil {
+            logger.Event("script_rejected").ExitCode(exitUsage).Printf("Скрипт бота отклонён: %v", err)
+            return exitUsage
+        }
+        if _, err := os.Stat(scriptPath); err != nil {
+            logger.Event("script_not_found").ExitCode(exitScriptNotFound).Notify().Printf("Не найден скрипт бота: %s", scriptPath)
+            return exitScriptNotFound
+        }
+        if err := checkRequiredFiles(scriptPath, cfg.RequiredFiles); err != nil {
+            logger.Event("install_broken").ExitCode(exitScriptNotFound).Notify().Printf("Установка бота неполная: %v", err)
+            return exitScriptNotFound
+        }
+        if opts.verifyHash || cfg.VerifyHash {
+            if err := verifyScriptHashes(scriptPath); err != nil {
+                logger.Event("integrity_failed").ExitCode(exitIntegrity).Printf("Проверка целостности бота не пройдена: %v", err)
+                return exitIntegrity
+            }
+            logger.Infof("Контрольные суммы файлов бота совпадают с %s", hashManifestName)
         }
     }
 
+    refreshPath()
+    candidates := defaultCandidates(baseDir)
+    pinned, pinnedSource := pinnedPythonPath(baseDir, opts, cfg)
+
+    var pythonExe, venvDir string
+    if pinned != "" {
+        if pythonExe, err = lookPinnedPython(pinned); err != nil {
+            logger.Event("interpreter_not_found").ExitCode(exitNoInterpreter).Notify().Printf("Интерпретатор Python из %s не может быть использован: %v", pinnedSource, err)
+            return exitNoInterpreter
+        }
+        logger.Infof("Интерпретатор задан явно (%s), поиск пропущен", pinnedSource)
+    }
+    if pythonExe == "" {
+        venvDir, pythonExe = findVenvPython(baseDir)
+    }
+    if pythonExe == "" {
+        pythonExe = findPython(candidates)
+    }
+    if pythonExe == "" && opts.bootstrapPython && opts.dryRun {
+        logger.Printf("Интерпретатор Python не найден; без --dry-run лаунчер загрузил бы встроенный Python")
+        return exitNoInterpreter
+    }
+    if pythonExe == "" && opts.bootstrapPython {
+        if err := bootstrapPython(baseDir, cfg.BootstrapPythonURL, cfg.BootstrapPythonSHA256); err != nil {
+            logger.Printf("Не удалось загрузить Python: %v", err)
+        } else {
+            pythonExe = findPython(candidates)
+        }
+    }
     if pythonExe == "" {
         message := "Не удалось найти интерпретатор Python. Установите Python 3.11+ или добавьте python.exe рядом с программой."
-        fmt.Fprintln(os.Stderr, message)
+        logger.Event("interpreter_not_found").ExitCode(exitNoInterpreter).Notify().Printf("%s", message)
         fmt.Fprintln(os.Stdout, message)
-        return
+        return exitNoInterpreter
     }
 
-    cmd := exec.Command(pythonExe, scriptPath)
-    cmd.Stdout = os.Stdout
-    cmd.Stderr = os.Stderr
-    cmd.Env = append(os.Environ(), "PYTHONUTF8=1")
+    if !opts.skipVersionCheck {
+        version, err := pythonVersion(pythonExe)
+        if err != nil {
+            // Интерпретатор, который не может выполнить --version, так же
+            // непригоден, как отсутствующий; код 13 остаётся за старой версией.
+            logger.Event("interpreter_broken").Interpreter(pythonExe).ExitCode(exitNoInterpreter).Notify().Printf("Python %s не запускается или не сообщает версию: %v. Переустановите Python или укажите другой интерпретатор в --python.", pythonExe, err)
+            return exitNoInterpreter
+        }
+        if version.less(minPythonVersion) {
+            logger.Event("version_too_old").Interpreter(pythonExe).ExitCode(exitVersionTooOld).Printf("Найден Python %s (%s), требуется %s или новее. Установите подходящую версию или используйте --skip-version-check.", version, pythonExe, minPythonVersion)
+            return exitVersionTooOld
+        }
+        logger.Infof("Версия Python: %s", version)
+    }
+
+    if cfg.RequiredPythonBits != 0 && cfg.RequiredPythonBits != 32 && cfg.RequiredPythonBits != 64 {
+        logger.Printf("Предупреждение: required_python_bits должен быть 32 или 64, проверка разрядности пропущена")
+    } else if cfg.RequiredPythonBits != 0 {
+        bits, err := pythonBits(pythonExe)
+        if err != nil {
+            logger.Printf("Не удалось определить разрядность Python %s: %v", pythonExe, err)
+            return exitArchMismatch
+        }
+        if bits != cfg.RequiredPythonBits {
+            logger.Event("arch_mismatch").Interpreter(pythonExe).ExitCode(exitArchMismatch).Notify().Printf("Найден %d-битный Python (%s), а боту нужен %d-битный: его модули не загрузятся. Установите Python нужной разрядности или укажите его в python_path.", bits, pythonExe, cfg.RequiredPythonBits)
+            return exitArchMismatch
+        }
+        logger.Infof("Разрядность Python: %d", bits)
+    }
+
+    shutdownTimeout := defaultShutdownTimeout
+    if cfg.ShutdownTimeoutSec != nil {
+        shutdownTimeout = time.Duration(*cfg.ShutdownTimeoutSec) * time.Second
+    }
+    if opts.shutdownTimeout > 0 {
+        shutdownTimeout = opts.shutdownTimeout
+    }
+    ctl.shutdownTimeout.Store(int64(shutdownTimeout))
+
+    configureConsole(opts.showConsole)
+    pidPath := filepath.Join(baseDir, pidFileName)
+    botTarget := scriptPath
+    if module != "" {
+        botTarget = "-m " + module
+    }
+    if !opts.dryRun {
+        terminateOrphan(pidPath, botTarget, shutdownTimeout)
+    }
+
+    logger.Event("interpreter_selected").Interpreter(pythonExe).Infof("Интерпретатор: %s", pythonExe)
+    if module != "" {
+        logger.Infof("Модуль: %s", module)
+    } else {
+        logger.Infof("Скрипт: %s", scriptPath)
+    }
+    if len(opts.scriptArgs) > 0 {
+        logger.Infof("Аргументы бота: %q", opts.scriptArgs)
+    }
+
+    env := botEnvironment(baseDir, opts, cfg, venvDir)
+
+    readyTimeout := time.Duration(cfg.ReadyTimeoutSec) * time.Second
+    if opts.readyTimeout > 0 {
+        readyTimeout = opts.readyTimeout
+    }
+    var readiness *readinessProbe
+    if readyTimeout > 0 {
+        readiness = &readinessProbe{marker: cfg.ReadyMarker, timeout: readyTimeout}
+        if cfg.ReadyFile != "" || cfg.ReadyMarker == "" {
+            readyFile := defaultReadyFileName
+            if cfg.ReadyFile != "" {
+                readyFile = cfg.ReadyFile
+            }
+            readiness.file = resolvePath(baseDir, readyFile)
+            env = append(env, readyFileEnvVar+"="+readiness.file)
+        }
+        logger.Infof("Ожидание готовности бота: до %s", readyTimeout)
+    }
+    for _, line := range envDiff(os.Environ(), env, cfg.Credentials) {
+        logger.Debugf("Окружение: %s", line)
+    }
+    if len(cfg.Credentials) > 0 {
+        secrets, err := credentialEnv(cfg.Credentials)
+        if err != nil {
+            logger.Event("secret_missing").ExitCode(exitSecretMissing).Notify().Printf("Не удалось получить секрет из диспетчера учётных данных: %v", err)
+            return exitSecretMissing
+        }
+        env = append(env, secrets...)
+        logger.Infof("Переменные из диспетчера учётных данных: %s", strings.Join(sortedKeys(cfg.Credentials), ", "))
+    }
+
+    output := consoleOutput(ctl.console)
+    if (opts.captureOutput || cfg.CaptureOutput) && !opts.dryRun {
+        captured, err := openBotOutput(baseDir, ctl.console)
+        if err != nil {
+            logger.Printf("Предупреждение: не удалось открыть файлы вывода бота: %v", err)
+        } else {
+            output = captured
+            defer captured.Close()
+        }
+    }
+    eventLogForward := cfg.EventLogForward
+    if opts.eventLogForward != "" {
+        eventLogForward = opts.eventLogForward
+    }
+    if err := validEventForwardMode(eventLogForward); err != nil {
+        logger.Printf("Предупреждение: eventlog_forward: %v", err)
+    } else if eventLogForward != "" {
+        if fw := openEventForwarder(eventLogForward); fw != nil {
+            output.stderr = io.MultiWriter(output.stderr, fw)
+            defer fw.Close()
+            logger.Infof("Вывод stderr бота пересылается в журнал событий (%s)", eventLogForward)
+        }
+    }
+
+    logStreamAddr := cfg.LogStreamAddr
+    if opts.logStream != "" {
+        logStreamAddr = opts.logStream
+    }
+    if logStreamAddr != "" && !opts.dryRun {
+        if stream, err := startLogStream(logStreamAddr); err != nil {
+            logger.Printf("Предупреждение: не удалось открыть поток журнала на %s: %v", logStreamAddr, err)
+        } else {
+            output.stdout = io.MultiWriter(stream.writer("stdout"), ignoreErrors{output.stdout})
+            output.stderr = io.MultiWriter(stream.writer("stderr"), ignoreErrors{output.stderr})
+            defer stream.Close()
+            logger.Infof("Вывод бота транслируется на tcp://%s", logStreamAddr)
+        }
+    }
+
+    var crash *crashReporter
+    crashLines := defaultCrashLogLines
+    if cfg.CrashLogLines != nil {
+        crashLines = *cfg.CrashLogLines
+    }
+    if crashLines > 0 {
+        crash = &crashReporter{dir: filepath.Join(baseDir, logDirName), tail: newStderrTail(crashLines), interpreter: pythonExe}
+        // Без перехвата stderr бота идёт прямо в консоль; ошибка записи в
+        // закрытую консоль не должна останавливать сбор хвоста.
+        output.stderr = io.MultiWriter(crash.tail, ignoreErrors{output.stderr})
+    }
+
+    workDir := botWorkDir(baseDir, scriptPath, module, cfg)
+    logger.Infof("Рабочий каталог бота: %s", workDir)
+    botArgs := append([]string{scriptPath}, opts.scriptArgs...)
+    if module != "" {
+        botArgs = append([]string{"-m", module}, opts.scriptArgs...)
+    }
+    if opts.unbuffered || cfg.Unbuffered {
+        botArgs = append([]string{"-u"}, botArgs...)
+    }
+    logger.Debugf("Команда: %s", formatCommandLine(pythonExe, botArgs))
+    if crash != nil {
+        crash.commandLine = formatCommandLine(pythonExe, botArgs)
+    }
+    newCmd := botCommand(pythonExe, botArgs, workDir, env, output)
+
+    if (opts.installDeps || cfg.InstallDeps) && !opts.dryRun {
+        if err := installDeps(pythonExe, baseDir, env); err != nil {
+            if errors.Is(err, errPipMissing) {
+                logger.Printf("Для %s не установлен pip. Выполните %s и запустите лаунчер снова.", pythonExe, formatCommandLine(pythonExe, []string{"-m", "ensurepip", "--upgrade"}))
+            } else {
+                logger.Printf("Не удалось установить зависимости: %v", err)
+            }
+            return exitDepsFailed
+        }
+    }
+
+    if module != "" {
+        if err := checkModuleImportable(pythonExe, module, workDir, env); err != nil {
+            logger.Event("script_not_found").ExitCode(exitScriptNotFound).Notify().Printf("Модуль бота %s не найден: %v", module, err)
+            return exitScriptNotFound
+        }
+    } else if err := checkScriptSyntax(pythonExe, scriptPath, env); err != nil {
+        logger.Event("script_broken").ExitCode(exitScriptNotFound).Notify().Printf("Скрипт бота повреждён и не может быть запущен: %v", err)
+        return exitScriptNotFound
+    }
+
+    policy := defaultRestartPolicy()
+    if cfg.MaxRestarts != nil {
+        policy.maxRestarts = *cfg.MaxRestarts
+    }
+    if opts.maxRestarts >= 0 {
+        policy.maxRestarts = opts.maxRestarts
+    }
+    policy.window = time.Duration(cfg.RestartWindowSec) * time.Second
+    if cfg.BackoffMinSec > 0 {
+        policy.minBackoff = time.Duration(cfg.BackoffMinSec) * time.Second
+    }
+    if cfg.BackoffMaxSec > 0 {
+        policy.maxBackoff = time.Duration(cfg.BackoffMaxSec) * time.Second
+    }
+    if policy.maxBackoff < policy.minBackoff {
+        policy.maxBackoff = policy.minBackoff
+    }
+    if policy.action, err = parseRestartAction(cfg.RestartAction); err != nil {
+        logger.Printf("Предупреждение: restart_action: %v, используется %s", err, restartExit)
+        policy.action = restartExit
+    }
+
+    if opts.dryRun {
+        plan := &launchPlan{pythonExe: pythonExe, botArgs: botArgs, workDir: workDir, env: env, secretKeys: cfg.Credentials, policy: policy}
+        if opts.installDeps || cfg.InstallDeps {
+            plan.steps = append(plan.steps, "установка зависимостей из "+requirementsFileName+", если они ещё не установлены")
+        }
+        if cfg.PreLaunch != "" {
+            plan.steps = append(plan.steps, "хук pre_launch: "+cfg.PreLaunch)
+        }
+        plan.print(os.Stdout)
+        return exitOK
+    }
+
+    if cfg.PreLaunch != "" {
+        if err := runHook("pre_launch", cfg.PreLaunch, baseDir, env); err != nil {
+            logger.Event("hook_failed").ExitCode(exitHookFailed).Printf("Хук pre_launch завершился с ошибкой, бот не запущен: %v", err)
+            return exitHookFailed
+        }
+    }
+
+    defer trackPIDFile(pidPath, ctl.status)()
+    defer trackStatusFile(filepath.Join(baseDir, statusFileName), ctl.status, launcherStarted)()
+
+    healthAddr := cfg.HealthAddr
+    if opts.healthAddr != "" {
+        healthAddr = opts.healthAddr
+    }
+    if healthAddr != "" {
+        stopHealth, err := startHealthServer(healthAddr, ctl.status)
+        if err != nil {
+            logger.Printf("Предупреждение: не удалось запустить проверку состояния на %s: %v", healthAddr, err)
+        } else {
+            logger.Infof("Проверка состояния доступна на http://%s/", healthAddr)
+            defer stopHealth()
+        }
+    }
+
+    if cfg.WatchdogMaxMemoryMB > 0 || cfg.WatchdogMaxCPUPercent > 0 {
+        w := &watchdog{
+            maxMemory: uint64(cfg.WatchdogMaxMemoryMB) << 20,
+            maxCPU:    cfg.WatchdogMaxCPUPercent,
+            interval:  defaultWatchdogInterval,
+            sustain:   defaultWatchdogSustain,
+            ctl:       ctl,
+        }
+        if cfg.WatchdogIntervalSec > 0 {
+            w.interval = time.Duration(cfg.WatchdogIntervalSec) * time.Second
+        }
+        if cfg.WatchdogSustainSec > 0 {
+            w.sustain = time.Duration(cfg.WatchdogSustainSec) * time.Second
+        }
+        stopWatchdog := make(chan struct{})
+        defer close(stopWatchdog)
+        go w.run(stopWatchdog)
+        logger.Infof("Контроль ресурсов бота включён: опрос раз в %s, допуск %s", w.interval, w.sustain)
+    }
+
+    if cfg.RestartAt != "" {
+        if at, err := parseDailyTime(cfg.RestartAt); err != nil {
+            logger.Printf("Предупреждение: restart_at: %v, плановый перезапуск отключён", err)
+        } else {
+            stopSchedule := make(chan struct{})
+            defer close(stopSchedule)
+            go runDailyRestart(at, ctl, stopSchedule)
+        }
+    }
+
+    if opts.watch {
+        watchDir := workDir
+        if module == "" {
+            watchDir = filepath.Dir(scriptPath)
+        }
+        check := func(path string) error { return checkScriptSyntax(pythonExe, path, env) }
+        if stopWatch, err := startSourceWatcher(watchDir, check, ctl); err != nil {
+            logger.Printf("Предупреждение: не удалось включить наблюдение за %s: %v", watchDir, err)
+        } else {
+            logger.Printf("Наблюдение за файлами бота в %s: бот перезапускается после изменения .py-файлов", watchDir)
+            defer stopWatch()
+        }
+    }
+
+    sup := &supervisor{
+        newCmd:          newCmd,
+        policy:          policy,
+        shutdownTimeout: shutdownTimeout,
+        ctl:             ctl,
+        interpreter:     pythonExe,
+        readiness:       readiness,
+        crash:           crash,
+    }
+    code := sup.run()
+    if cfg.PostExit != "" {
+        runPostExitHook(cfg.PostExit, baseDir, env, code)
+    }
+    return code
+}
+
+// botWorkDir — рабочий каталог бота: каталог скрипта, чтобы относительные
+// пути бота не зависели от того, откуда запущен лаунчер (например, из
+// планировщика заданий), а при запуске модуля — каталог лаунчера.
+// working_dir из конфигурации перекрывает оба варианта.
+func botWorkDir(baseDir, scriptPath, module string, cfg *Config) string {
+    if cfg.WorkingDir != "" {
+        return resolvePath(baseDir, cfg.WorkingDir)
+    }
+    if module != "" {
+        return baseDir
+    }
+    return filepath.Dir(scriptPath)
+}
 
-    if err := cmd.Run(); err != nil {
-        fmt.Fprintf(os.Stderr, "Ошибка запуска python скрипта: %v\n", err)
+// botCommand возвращает функцию, которая собирает команду запуска бота;
+// супервизор вызывает её заново при каждом перезапуске.
+func botCommand(pythonExe string, botArgs []string, workDir string, env []string, output *botOutput) func() *exec.Cmd {
+    return func() *exec.Cmd {
+        cmd := exec.Command(pythonExe, botArgs...)
+        cmd.Dir = workDir
+        cmd.Stdout = output.stdout
+        cmd.Stderr = output.stderr
+        cmd.Env = env
+        return cmd
     }
 }
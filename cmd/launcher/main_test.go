@@ -0,0 +1,89 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// TestHelperProcess не тест: его запускают как дочерний процесс, чтобы
+// проверить, в каком каталоге оказывается бот. Печатает текущий каталог.
+func TestHelperProcess(t *testing.T) {
+    if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+        return
+    }
+    dir, err := os.Getwd()
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+    fmt.Print(dir)
+    os.Exit(0)
+}
+
+func TestBotWorkDir(t *testing.T) {
+    base := filepath.Join(t.TempDir(), "launcher")
+    script := filepath.Join(base, "bot_app", "main.py")
+    other := filepath.Join(t.TempDir(), "data")
+    tests := []struct {
+        name       string
+        module     string
+        workingDir string
+        want       string
+    }{
+        {"скрипт", "", "", filepath.Dir(script)},
+        {"модуль", "bot_app", "", base},
+        {"относительный working_dir", "", "data", filepath.Join(base, "data")},
+        {"абсолютный working_dir", "bot_app", other, other},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := botWorkDir(base, script, tt.module, &Config{WorkingDir: tt.workingDir})
+            if got != tt.want {
+                t.Errorf("botWorkDir() = %q, ожидалось %q", got, tt.want)
+            }
+        })
+    }
+}
+
+// TestChildWorkDirIgnoresLauncherCwd собирает команду бота так же, как
+// launch, из постороннего текущего каталога и проверяет, что дочерний
+// процесс работает в каталоге скрипта.
+func TestChildWorkDirIgnoresLauncherCwd(t *testing.T) {
+    base := t.TempDir()
+    script := filepath.Join(base, "bot_app", "main.py")
+    if err := os.MkdirAll(filepath.Dir(script), 0o755); err != nil {
+        t.Fatal(err)
+    }
+    t.Chdir(t.TempDir())
+
+    var stdout, stderr bytes.Buffer
+    env := append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+    newCmd := botCommand(os.Args[0], []string{"-test.run=^TestHelperProcess$"}, botWorkDir(base, script, "", &Config{}), env, &botOutput{stdout: &stdout, stderr: &stderr})
+    cmd := newCmd()
+    if cmd.Dir != filepath.Dir(script) {
+        t.Errorf("cmd.Dir = %q, ожидался %q", cmd.Dir, filepath.Dir(script))
+    }
+    if err := cmd.Run(); err != nil {
+        t.Fatalf("дочерний процесс: %v\n%s", err, stderr.String())
+    }
+
+    got, want := evalPath(t, strings.TrimSpace(stdout.String())), evalPath(t, filepath.Dir(script))
+    if !strings.EqualFold(got, want) {
+        t.Errorf("рабочий каталог дочернего процесса %q, ожидался %q", got, want)
+    }
+}
+
+// evalPath раскрывает символические ссылки: временный каталог в macOS
+// лежит в /var, который ссылается на /private/var.
+func evalPath(t *testing.T, path string) string {
+    t.Helper()
+    resolved, err := filepath.EvalSymlinks(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return resolved
+}
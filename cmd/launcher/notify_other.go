@@ -0,0 +1,7 @@
+//go:build !windows
+
+package main
+
+// showFailureNotification — уведомления есть только в Windows; в
+// остальных системах сообщение уже выведено в консоль и журнал.
+func showFailureNotification(message string) {}
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "os"
+    "os/exec"
+    "sync"
+    "syscall"
+    "time"
+
+    "golang.org/x/sys/windows"
+    "golang.org/x/sys/windows/svc"
+)
+
+const (
+    notificationTitle   = "Бот ЕГАИС не запущен"
+    notificationTimeout = 15 * time.Second
+    // notificationInterval — не чаще одного уведомления за этот период.
+    notificationInterval = 30 * time.Second
+    // powerShellAppID — идентификатор приложения, от имени которого
+    // PowerShell может показывать всплывающие уведомления без регистрации
+    // собственного AppUserModelID.
+    powerShellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+)
+
+// toastScript показывает уведомление через WinRT ToastNotificationManager.
+// Текст передаётся через переменные окружения, чтобы не экранировать его
+// внутри скрипта.
+const toastScript = `$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:EGAIS_TOAST_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:EGAIS_TOAST_TEXT)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:EGAIS_TOAST_APP).Show($toast)`
+
+// notifier ограничивает частоту уведомлений: при цикле падений бота
+// кассиру не нужна стопка одинаковых окон, а лаунчеру — по PowerShell на
+// каждую попытку.
+var notifier struct {
+    mu   sync.Mutex
+    last time.Time
+}
+
+// inService — у службы нет рабочего стола, уведомление всё равно не
+// появится. Проверка делается один раз.
+var inService = sync.OnceValue(func() bool {
+    isService, err := svc.IsWindowsService()
+    return err == nil && isService
+})
+
+// showFailureNotification показывает всплывающее уведомление Windows с
+// текстом ошибки. Если уведомления недоступны (старая Windows, служба без
+// рабочего стола), остаётся только вывод в консоль и журнал. PowerShell
+// запускается сразу, чтобы уведомление появилось, даже если лаунчер тут же
+// завершится, но его завершения лаунчер не ждёт.
+func showFailureNotification(message string) {
+    if inService() {
+        return
+    }
+    notifier.mu.Lock()
+    if since := time.Since(notifier.last); !notifier.last.IsZero() && since < notificationInterval {
+        notifier.mu.Unlock()
+        logger.Debugf("Уведомление пропущено: предыдущее показано %s назад", since.Round(time.Second))
+        return
+    }
+    notifier.last = time.Now()
+    notifier.mu.Unlock()
+
+    ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+    cmd := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-WindowStyle", "Hidden", "-Command", toastScript)
+    cmd.Env = append(os.Environ(),
+        "EGAIS_TOAST_TITLE="+notificationTitle,
+        "EGAIS_TOAST_TEXT="+message,
+        "EGAIS_TOAST_APP="+powerShellAppID,
+    )
+    cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: windows.CREATE_NO_WINDOW}
+    var out bytes.Buffer
+    cmd.Stdout = &out
+    cmd.Stderr = &out
+    if err := cmd.Start(); err != nil {
+        cancel()
+        logger.Debugf("Уведомление не показано: %v", err)
+        return
+    }
+    go func() {
+        defer cancel()
+        if err := cmd.Wait(); err != nil {
+            logger.Debugf("Уведомление не показано: %v: %s", err, out.Bytes())
+        }
+    }()
+}
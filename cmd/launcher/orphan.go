@@ -0,0 +1,86 @@
+package main
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const orphanPollInterval = 200 * time.Millisecond
+
+// terminateOrphan завершает бота, оставшегося от аварийно завершённого
+// лаунчера. PID берётся из launcher.pid; процесс останавливается, только
+// если он жив и его командная строка содержит путь к нашему скрипту,
+// чтобы не задеть посторонний процесс, получивший тот же PID.
+func terminateOrphan(pidPath, scriptPath string, timeout time.Duration) {
+    data, err := os.ReadFile(pidPath)
+    if errors.Is(err, os.ErrNotExist) {
+        return
+    }
+    if err != nil {
+        logger.Printf("Не удалось прочитать %s: %v", pidPath, err)
+        return
+    }
+    pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+    if err != nil || pid <= 0 || pid == os.Getpid() {
+        logger.Printf("Файл %s повреждён и будет удалён", pidPath)
+        removePIDFile(pidPath)
+        return
+    }
+    if !processAlive(pid) {
+        logger.Infof("Процесс бота из %s (PID %d) уже завершён", pidPath, pid)
+        removePIDFile(pidPath)
+        return
+    }
+
+    cmdline, err := processCommandLine(pid)
+    if err != nil {
+        logger.Printf("Не удалось проверить процесс PID %d из %s, он не будет остановлен: %v", pid, pidPath, err)
+        return
+    }
+    if !commandLineMentions(cmdline, scriptPath) {
+        logger.Printf("PID %d из %s принадлежит другому процессу (%s), он не будет остановлен", pid, pidPath, cmdline)
+        removePIDFile(pidPath)
+        return
+    }
+
+    logger.Event("orphan_found").Printf("Найден оставшийся процесс бота PID %d, он будет остановлен", pid)
+    stopOrphan(pid, timeout)
+    removePIDFile(pidPath)
+}
+
+func stopOrphan(pid int, timeout time.Duration) {
+    p, err := os.FindProcess(pid)
+    if err != nil {
+        logger.Printf("Не удалось открыть процесс PID %d: %v", pid, err)
+        return
+    }
+    defer p.Release()
+    if err := interruptProcess(p); err == nil {
+        deadline := time.Now().Add(timeout)
+        for time.Now().Before(deadline) {
+            if !processAlive(pid) {
+                logger.Printf("Оставшийся процесс бота PID %d завершён", pid)
+                return
+            }
+            time.Sleep(orphanPollInterval)
+        }
+    }
+    if err := p.Kill(); err != nil && processAlive(pid) {
+        logger.Printf("Не удалось завершить процесс PID %d: %v", pid, err)
+        return
+    }
+    logger.Printf("Оставшийся процесс бота PID %d завершён принудительно", pid)
+}
+
+func commandLineMentions(cmdline, path string) bool {
+    path = filepath.Clean(path)
+    if runtime.GOOS == "windows" {
+        return strings.Contains(strings.ToLower(cmdline), strings.ToLower(path))
+    }
+    return strings.Contains(cmdline, path)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+
+    "golang.org/x/sys/windows/registry"
+)
+
+const systemEnvKey = `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+
+// refreshPath дополняет PATH процесса актуальными значениями Path из
+// реестра (системным и пользовательским). Лаунчер, запущенный из старого
+// сеанса Проводника, иначе не видит только что установленный Python.
+func refreshPath() {
+    var fresh []string
+    for _, src := range []struct {
+        root registry.Key
+        path string
+    }{
+        {registry.LOCAL_MACHINE, systemEnvKey},
+        {registry.CURRENT_USER, "Environment"},
+    } {
+        value, err := readRegistryPath(src.root, src.path)
+        if err != nil {
+            logger.Debugf("Не удалось прочитать Path из реестра (%s): %v", src.path, err)
+            continue
+        }
+        fresh = append(fresh, filepath.SplitList(value)...)
+    }
+    if len(fresh) == 0 {
+        return
+    }
+
+    current := filepath.SplitList(os.Getenv("PATH"))
+    merged := mergePathLists(current, fresh)
+    if len(merged) == len(current) {
+        return
+    }
+    logger.Debugf("PATH дополнен значениями из реестра: %d новых каталогов", len(merged)-len(current))
+    os.Setenv("PATH", strings.Join(merged, string(os.PathListSeparator)))
+}
+
+func readRegistryPath(root registry.Key, path string) (string, error) {
+    k, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+    if err != nil {
+        return "", err
+    }
+    defer k.Close()
+    value, valType, err := k.GetStringValue("Path")
+    if err != nil {
+        return "", err
+    }
+    if valType == registry.EXPAND_SZ {
+        if expanded, err := registry.ExpandString(value); err == nil {
+            value = expanded
+        }
+    }
+    return value, nil
+}
+
+// mergePathLists оставляет текущий порядок каталогов и добавляет в конец
+// каталоги из fresh, которых ещё нет (без учёта регистра и завершающего \).
+func mergePathLists(current, fresh []string) []string {
+    seen := make(map[string]bool, len(current)+len(fresh))
+    merged := make([]string, 0, len(current)+len(fresh))
+    for _, list := range [][]string{current, fresh} {
+        for _, dir := range list {
+            dir = strings.TrimSpace(dir)
+            key := strings.ToLower(strings.TrimRight(dir, `\/`))
+            if dir == "" || seen[key] {
+                continue
+            }
+            seen[key] = true
+            merged = append(merged, dir)
+        }
+    }
+    return merged
+}
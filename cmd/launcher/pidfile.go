@@ -0,0 +1,55 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+)
+
+const pidFileName = "launcher.pid"
+
+// trackPIDFile держит в path PID работающего процесса бота: файл
+// перезаписывается при каждом запуске и удаляется, когда бот не работает.
+// PID самого лаунчера записан в launcher.lock.
+func trackPIDFile(path string, status *botStatus) (cleanup func()) {
+    written := 0
+    status.subscribe(func(snap statusSnapshot) {
+        if snap.State == stateRunning && snap.PID > 0 {
+            if snap.PID == written {
+                return
+            }
+            if err := writeFileAtomic(path, []byte(strconv.Itoa(snap.PID)+"\n")); err != nil {
+                logger.Printf("Не удалось записать %s: %v", path, err)
+                return
+            }
+            written = snap.PID
+            return
+        }
+        if written != 0 {
+            removePIDFile(path)
+            written = 0
+        }
+    })
+    return func() {
+        removePIDFile(path)
+    }
+}
+
+func removePIDFile(path string) {
+    if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+        logger.Printf("Не удалось удалить %s: %v", path, err)
+    }
+}
+
+func writeFileAtomic(path string, data []byte) error {
+    tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+    if err := os.WriteFile(tmp, data, 0o644); err != nil {
+        return err
+    }
+    if err := os.Rename(tmp, path); err != nil {
+        os.Remove(tmp)
+        return err
+    }
+    return nil
+}
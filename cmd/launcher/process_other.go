@@ -0,0 +1,92 @@
+//go:build !windows
+
+package main
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "strconv"
+    "strings"
+    "syscall"
+    "time"
+)
+
+func processAlive(pid int) bool {
+    err := syscall.Kill(pid, 0)
+    return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// configureConsole — консольные окна есть только в Windows.
+func configureConsole(showConsole bool) {}
+
+// configureChild помещает бота в отдельную группу процессов, чтобы SIGINT
+// от терминала не доходил до него в обход лаунчера.
+func configureChild(cmd *exec.Cmd) {
+    cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func interruptProcess(p *os.Process) error {
+    return p.Signal(os.Interrupt)
+}
+
+func processCommandLine(pid int) (string, error) {
+    data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline")
+    if err == nil {
+        return strings.TrimSpace(string(bytes.ReplaceAll(data, []byte{0}, []byte{' '}))), nil
+    }
+    out, err := exec.Command("ps", "-o", "args=", "-p", strconv.Itoa(pid)).Output()
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimSpace(string(out)), nil
+}
+
+// processUsage возвращает резидентную память и суммарное процессорное
+// время процесса: из /proc в Linux, через ps в остальных системах.
+func processUsage(pid int) (rss uint64, cpu time.Duration, err error) {
+    if stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat"); err == nil {
+        // Имя процесса в скобках может содержать пробелы, поля считаем после него.
+        fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+        if len(fields) > 21 {
+            utime, _ := strconv.ParseUint(fields[11], 10, 64)
+            stime, _ := strconv.ParseUint(fields[12], 10, 64)
+            pages, _ := strconv.ParseUint(fields[21], 10, 64)
+            cpu = time.Duration(utime+stime) * time.Second / clockTicks
+            return pages * uint64(os.Getpagesize()), cpu, nil
+        }
+    }
+    out, err := exec.Command("ps", "-o", "rss=,time=", "-p", strconv.Itoa(pid)).Output()
+    if err != nil {
+        return 0, 0, err
+    }
+    fields := strings.Fields(string(out))
+    if len(fields) != 2 {
+        return 0, 0, fmt.Errorf("неожиданный вывод ps: %q", out)
+    }
+    kb, err := strconv.ParseUint(fields[0], 10, 64)
+    if err != nil {
+        return 0, 0, err
+    }
+    return kb << 10, parsePSTime(fields[1]), nil
+}
+
+// clockTicks — USER_HZ ядра Linux, почти всегда 100.
+const clockTicks = 100
+
+// parsePSTime разбирает время ps в формате [[дд-]чч:]мм:сс[.доли].
+func parsePSTime(s string) time.Duration {
+    var days int
+    if d, rest, ok := strings.Cut(s, "-"); ok {
+        days, _ = strconv.Atoi(d)
+        s = rest
+    }
+    var total float64
+    for _, part := range strings.Split(s, ":") {
+        v, _ := strconv.ParseFloat(part, 64)
+        total = total*60 + v
+    }
+    return time.Duration(days)*24*time.Hour + time.Duration(total*float64(time.Second))
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParsePSTime(t *testing.T) {
+    tests := []struct {
+        s    string
+        want time.Duration
+    }{
+        {"00:05", 5 * time.Second},
+        {"01:30", 90 * time.Second},
+        {"02:01:30", 2*time.Hour + 90*time.Second},
+        {"3-04:00:00", 3*24*time.Hour + 4*time.Hour},
+        {"00:01.50", 1500 * time.Millisecond},
+        {"0:00", 0},
+    }
+    for _, tt := range tests {
+        if got := parsePSTime(tt.s); got != tt.want {
+            t.Errorf("parsePSTime(%q) = %v, ожидалось %v", tt.s, got, tt.want)
+        }
+    }
+}
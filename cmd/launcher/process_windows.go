@@ -0,0 +1,150 @@
+package main
+
+import (
+    "os"
+    "os/exec"
+    "sync"
+    "syscall"
+    "time"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+const (
+    processQueryLimitedInformation = 0x1000
+    stillActive                    = 259
+)
+
+func processAlive(pid int) bool {
+    h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+    if err != nil {
+        // ERROR_ACCESS_DENIED означает, что процесс существует, но чужой.
+        return err == syscall.ERROR_ACCESS_DENIED
+    }
+    defer syscall.CloseHandle(h)
+    var code uint32
+    if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+        return true
+    }
+    return code == stillActive
+}
+
+var (
+    kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+    procGenerateConsoleCtrlEvent = kernel32.NewProc("GenerateConsoleCtrlEvent")
+    procGetConsoleWindow         = kernel32.NewProc("GetConsoleWindow")
+    procAttachConsole            = kernel32.NewProc("AttachConsole")
+    procFreeConsole              = kernel32.NewProc("FreeConsole")
+    procGetProcessMemoryInfo     = kernel32.NewProc("K32GetProcessMemoryInfo")
+)
+
+var (
+    // hideChildConsole — бот запускается со скрытой консолью: у лаунчера
+    // нет своей консоли (сборка -H windowsgui, служба), и python.exe иначе
+    // открыл бы новое окно поверх кассового интерфейса.
+    hideChildConsole bool
+    consoleMu        sync.Mutex
+)
+
+// configureConsole решает, скрывать ли консоль бота. Если консоль у
+// лаунчера есть, бот делит её с ним, как и раньше; showConsole оставляет
+// боту видимое окно для отладки.
+func configureConsole(showConsole bool) {
+    r, _, _ := procGetConsoleWindow.Call()
+    hideChildConsole = r == 0 && !showConsole
+}
+
+// configureChild запускает бота в отдельной группе процессов, чтобы Ctrl+C
+// из консоли получал только лаунчер и сам решал, как остановить бота.
+// DETACHED_PROCESS не используется: процессу без консоли нельзя доставить
+// CTRL_BREAK, а скрытая консоль CREATE_NO_WINDOW это позволяет.
+func configureChild(cmd *exec.Cmd) {
+    flags := uint32(syscall.CREATE_NEW_PROCESS_GROUP)
+    if hideChildConsole {
+        flags |= windows.CREATE_NO_WINDOW
+    }
+    cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: flags}
+}
+
+// interruptProcess отправляет CTRL_BREAK группе процессов бота: CTRL_C
+// для процессов, запущенных с CREATE_NEW_PROCESS_GROUP, отключён. Событие
+// доставляется только процессам той же консоли, поэтому к скрытой консоли
+// бота лаунчер на время отправки подключается сам.
+func interruptProcess(p *os.Process) error {
+    if hideChildConsole {
+        consoleMu.Lock()
+        defer consoleMu.Unlock()
+        if r, _, err := procAttachConsole.Call(uintptr(p.Pid)); r == 0 {
+            return err
+        }
+        defer procFreeConsole.Call()
+    }
+    r, _, err := procGenerateConsoleCtrlEvent.Call(syscall.CTRL_BREAK_EVENT, uintptr(p.Pid))
+    if r == 0 {
+        return err
+    }
+    return nil
+}
+
+func processCommandLine(pid int) (string, error) {
+    h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+    if err != nil {
+        return "", err
+    }
+    defer windows.CloseHandle(h)
+
+    buf := make([]byte, 4096)
+    for {
+        var needed uint32
+        err := windows.NtQueryInformationProcess(h, windows.ProcessCommandLineInformation, unsafe.Pointer(&buf[0]), uint32(len(buf)), &needed)
+        if err == windows.STATUS_INFO_LENGTH_MISMATCH && int(needed) > len(buf) {
+            buf = make([]byte, needed)
+            continue
+        }
+        if err != nil {
+            return "", err
+        }
+        return (*windows.NTUnicodeString)(unsafe.Pointer(&buf[0])).String(), nil
+    }
+}
+
+// processMemoryCounters повторяет PROCESS_MEMORY_COUNTERS из psapi.h.
+type processMemoryCounters struct {
+    cb                         uint32
+    PageFaultCount             uint32
+    PeakWorkingSetSize         uintptr
+    WorkingSetSize             uintptr
+    QuotaPeakPagedPoolUsage    uintptr
+    QuotaPagedPoolUsage        uintptr
+    QuotaPeakNonPagedPoolUsage uintptr
+    QuotaNonPagedPoolUsage     uintptr
+    PagefileUsage              uintptr
+    PeakPagefileUsage          uintptr
+}
+
+// processUsage возвращает рабочий набор и суммарное процессорное время
+// процесса.
+func processUsage(pid int) (rss uint64, cpu time.Duration, err error) {
+    h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+    if err != nil {
+        return 0, 0, err
+    }
+    defer windows.CloseHandle(h)
+
+    var mem processMemoryCounters
+    mem.cb = uint32(unsafe.Sizeof(mem))
+    if r, _, err := procGetProcessMemoryInfo.Call(uintptr(h), uintptr(unsafe.Pointer(&mem)), uintptr(mem.cb)); r == 0 {
+        return 0, 0, err
+    }
+    var creation, exit, kernel, user windows.Filetime
+    if err := windows.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+        return 0, 0, err
+    }
+    return uint64(mem.WorkingSetSize), filetimeDuration(kernel) + filetimeDuration(user), nil
+}
+
+// filetimeDuration переводит интервал FILETIME (в единицах по 100 нс).
+func filetimeDuration(ft windows.Filetime) time.Duration {
+    return time.Duration(uint64(ft.HighDateTime)<<32|uint64(ft.LowDateTime)) * 100
+}
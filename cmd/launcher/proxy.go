@@ -0,0 +1,103 @@
+package main
+
+import (
+    "runtime"
+    "strings"
+)
+
+// proxySettings — прокси для исходящих запросов бота.
+type proxySettings struct {
+    http, https string
+    noProxy     string
+}
+
+// resolveProxy выбирает прокси: явные proxy_url и no_proxy из
+// launcher.json, иначе, если включено proxy_from_system, системные
+// настройки Windows.
+func resolveProxy(cfg *Config) (proxySettings, bool) {
+    if cfg.ProxyURL != "" {
+        return proxySettings{http: cfg.ProxyURL, https: cfg.ProxyURL, noProxy: cfg.NoProxy}, true
+    }
+    if !cfg.ProxyFromSystem {
+        return proxySettings{}, false
+    }
+    p, ok := systemProxy()
+    if !ok {
+        logger.Printf("Предупреждение: системный прокси не настроен, переменные прокси не заданы")
+        return proxySettings{}, false
+    }
+    if cfg.NoProxy != "" {
+        p.noProxy = cfg.NoProxy
+    }
+    return p, true
+}
+
+// applyProxy выставляет стандартные переменные HTTP_PROXY, HTTPS_PROXY и
+// NO_PROXY. В Linux и macOS часть библиотек читает только имена в нижнем
+// регистре, поэтому задаются оба варианта; в Windows имена переменных
+// регистронезависимы.
+func applyProxy(env []string, p proxySettings) []string {
+    set := func(key, value string) {
+        if value == "" {
+            return
+        }
+        env = setEnv(env, key, value)
+        if runtime.GOOS != "windows" {
+            env = setEnv(env, strings.ToLower(key), value)
+        }
+    }
+    set("HTTP_PROXY", p.http)
+    set("HTTPS_PROXY", p.https)
+    set("NO_PROXY", p.noProxy)
+    return env
+}
+
+// parseWindowsProxyServer разбирает значение ProxyServer из настроек
+// Интернета: "host:port" для всех протоколов или
+// "http=host:port;https=host:port".
+func parseWindowsProxyServer(server string) (httpProxy, httpsProxy string) {
+    if !strings.Contains(server, "=") {
+        u := proxyURL(server)
+        return u, u
+    }
+    for _, part := range strings.Split(server, ";") {
+        scheme, addr, ok := strings.Cut(strings.TrimSpace(part), "=")
+        if !ok || addr == "" {
+            continue
+        }
+        switch strings.ToLower(scheme) {
+        case "http":
+            httpProxy = proxyURL(addr)
+        case "https":
+            httpsProxy = proxyURL(addr)
+        }
+    }
+    if httpsProxy == "" {
+        httpsProxy = httpProxy
+    }
+    return httpProxy, httpsProxy
+}
+
+func proxyURL(addr string) string {
+    addr = strings.TrimSpace(addr)
+    if addr == "" || strings.Contains(addr, "://") {
+        return addr
+    }
+    return "http://" + addr
+}
+
+// parseWindowsProxyOverride переводит ProxyOverride ("*.local;<local>")
+// в формат NO_PROXY.
+func parseWindowsProxyOverride(override string) string {
+    var hosts []string
+    for _, h := range strings.Split(override, ";") {
+        switch h = strings.TrimSpace(h); h {
+        case "":
+        case "<local>":
+            hosts = append(hosts, "localhost", "127.0.0.1", "::1")
+        default:
+            hosts = append(hosts, strings.TrimPrefix(h, "*"))
+        }
+    }
+    return strings.Join(hosts, ",")
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// systemProxy — системные настройки прокси читаются только в Windows.
+func systemProxy() (proxySettings, bool) {
+    return proxySettings{}, false
+}
@@ -0,0 +1,28 @@
+package main
+
+import "golang.org/x/sys/windows/registry"
+
+const internetSettingsKey = `Software\Microsoft\Windows\CurrentVersion\Internet Settings`
+
+// systemProxy читает прокси из настроек Интернета текущего пользователя
+// (Параметры → Сеть → Прокси).
+func systemProxy() (proxySettings, bool) {
+    k, err := registry.OpenKey(registry.CURRENT_USER, internetSettingsKey, registry.QUERY_VALUE)
+    if err != nil {
+        return proxySettings{}, false
+    }
+    defer k.Close()
+    if enabled, _, err := k.GetIntegerValue("ProxyEnable"); err != nil || enabled == 0 {
+        return proxySettings{}, false
+    }
+    server, _, err := k.GetStringValue("ProxyServer")
+    if err != nil || server == "" {
+        return proxySettings{}, false
+    }
+    var p proxySettings
+    p.http, p.https = parseWindowsProxyServer(server)
+    if override, _, err := k.GetStringValue("ProxyOverride"); err == nil {
+        p.noProxy = parseWindowsProxyOverride(override)
+    }
+    return p, p.http != "" || p.https != ""
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "regexp"
+    "runtime"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    versionCheckTimeout = 15 * time.Second
+    pythonEnvVar        = "EGAIS_PYTHON"
+)
+
+var (
+    minPythonVersion = pyVersion{major: 3, minor: 11}
+    pyVersionPattern = regexp.MustCompile(`Python\s+(\d+)\.(\d+)`)
+)
+
+type pyVersion struct {
+    major, minor int
+}
+
+func (v pyVersion) String() string {
+    return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+func (v pyVersion) less(other pyVersion) bool {
+    if v.major != other.major {
+        return v.major < other.major
+    }
+    return v.minor < other.minor
+}
+
+// defaultCandidates возвращает интерпретаторы в порядке поиска для текущей
+// ОС: сначала встроенный рантайм в папке python рядом с лаунчером, затем
+// PATH. На Windows оконный pythonw предпочтительнее, чтобы бот не открывал
+// консоль; на Linux и macOS такого варианта нет.
+func defaultCandidates(baseDir string) []string {
+    return candidatesFor(runtime.GOOS, baseDir)
+}
+
+// candidatesFor — список defaultCandidates для заданной GOOS.
+func candidatesFor(goos, baseDir string) []string {
+    bundled := filepath.Join(baseDir, "python")
+    if goos == "windows" {
+        return []string{
+            filepath.Join(bundled, "pythonw.exe"),
+            filepath.Join(bundled, "python.exe"),
+            "pythonw",
+            "python",
+        }
+    }
+    return []string{
+        filepath.Join(bundled, "bin", "python3"),
+        filepath.Join(bundled, "bin", "python"),
+        "python3",
+        "python",
+    }
+}
+
+// pinnedPythonPath возвращает явно заданный интерпретатор и его источник:
+// --python, переменная EGAIS_PYTHON или python_path из launcher.json.
+// Имя без каталога (python3.11) ищется в PATH, относительный путь
+// считается от каталога лаунчера. Пустой путь означает обычный поиск.
+func pinnedPythonPath(baseDir string, opts *options, cfg *Config) (path, source string) {
+    switch {
+    case opts.python != "":
+        path, source = opts.python, "--python"
+    case os.Getenv(pythonEnvVar) != "":
+        path, source = os.Getenv(pythonEnvVar), pythonEnvVar
+    case cfg.PythonPath != "":
+        return resolvePath(baseDir, cfg.PythonPath), configFileName
+    default:
+        return "", ""
+    }
+    if strings.ContainsAny(path, `/\`) {
+        path = resolvePath(baseDir, path)
+    }
+    return path, source
+}
+
+// lookPinnedPython проверяет явно заданный интерпретатор. В отличие от
+// findPython, причина отказа возвращается, а не пропускается.
+func lookPinnedPython(path string) (string, error) {
+    found, err := exec.LookPath(path)
+    switch {
+    case errors.Is(err, fs.ErrPermission):
+        return "", fmt.Errorf("%s не является исполняемым файлом", path)
+    case errors.Is(err, fs.ErrNotExist), errors.Is(err, exec.ErrNotFound):
+        return "", fmt.Errorf("%s не найден", path)
+    case err != nil:
+        return "", err
+    }
+    if abs, err := filepath.Abs(found); err == nil {
+        found = abs
+    }
+    return found, nil
+}
+
+func findPython(candidates []string) string {
+    for _, candidate := range candidates {
+        path, err := exec.LookPath(candidate)
+        if errors.Is(err, fs.ErrPermission) {
+            logger.Printf("Предупреждение: %s найден, но не является исполняемым файлом (выполните chmod +x)", candidate)
+            continue
+        }
+        if err != nil {
+            logger.Debugf("Кандидат %s: не найден (%v)", candidate, err)
+            continue
+        }
+        if abs, err := filepath.Abs(path); err == nil {
+            path = abs
+        }
+        logger.Debugf("Кандидат %s: найден %s", candidate, path)
+        return path
+    }
+    return ""
+}
+
+// pythonVersion запускает интерпретатор с --version. Python 2 печатает
+// версию в stderr, Python 3 — в stdout, поэтому читаем оба потока.
+func pythonVersion(pythonExe string) (pyVersion, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+    defer cancel()
+    out, err := exec.CommandContext(ctx, pythonExe, "--version").CombinedOutput()
+    logger.Debugf("%s --version: %q", pythonExe, strings.TrimSpace(string(out)))
+    if err != nil {
+        return pyVersion{}, err
+    }
+    return parsePythonVersion(string(out))
+}
+
+// pythonBits возвращает разрядность интерпретатора (32 или 64) по
+// sys.maxsize: имя exe и PATH не говорят, какой Python установлен.
+func pythonBits(pythonExe string) (int, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+    defer cancel()
+    out, err := exec.CommandContext(ctx, pythonExe, "-c", "import sys; print(64 if sys.maxsize > 2**32 else 32)").CombinedOutput()
+    logger.Debugf("%s: разрядность %q", pythonExe, strings.TrimSpace(string(out)))
+    if err != nil {
+        return 0, err
+    }
+    bits, err := strconv.Atoi(strings.TrimSpace(string(out)))
+    if err != nil {
+        return 0, fmt.Errorf("неожиданный вывод %q", out)
+    }
+    return bits, nil
+}
+
+func parsePythonVersion(output string) (pyVersion, error) {
+    m := pyVersionPattern.FindStringSubmatch(output)
+    if m == nil {
+        return pyVersion{}, fmt.Errorf("неожиданный вывод %q", output)
+    }
+    major, _ := strconv.Atoi(m[1])
+    minor, _ := strconv.Atoi(m[2])
+    return pyVersion{major: major, minor: minor}, nil
+}
+
+// formatCommandLine собирает командную строку для журнала и отчёта о сбое
+// с кавычками по правилам текущей ОС, чтобы её можно было скопировать и
+// выполнить без правок, даже если в путях есть пробелы.
+func formatCommandLine(name string, args []string) string {
+    parts := make([]string, 0, len(args)+1)
+    for _, a := range append([]string{name}, args...) {
+        parts = append(parts, quoteArg(a))
+    }
+    return strings.Join(parts, " ")
+}
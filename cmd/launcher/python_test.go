@@ -0,0 +1,46 @@
+package main
+
+import (
+    "path/filepath"
+    "slices"
+    "testing"
+)
+
+func TestCandidatesFor(t *testing.T) {
+    base := filepath.Join("opt", "egais")
+    bundled := filepath.Join(base, "python")
+    unix := []string{
+        filepath.Join(bundled, "bin", "python3"),
+        filepath.Join(bundled, "bin", "python"),
+        "python3",
+        "python",
+    }
+    tests := []struct {
+        goos string
+        want []string
+    }{
+        {"windows", []string{
+            filepath.Join(bundled, "pythonw.exe"),
+            filepath.Join(bundled, "python.exe"),
+            "pythonw",
+            "python",
+        }},
+        {"linux", unix},
+        {"darwin", unix},
+    }
+    for _, tt := range tests {
+        t.Run(tt.goos, func(t *testing.T) {
+            got := candidatesFor(tt.goos, base)
+            if !slices.Equal(got, tt.want) {
+                t.Errorf("candidatesFor(%q) = %q, ожидалось %q", tt.goos, got, tt.want)
+            }
+            seen := make(map[string]bool)
+            for _, c := range got {
+                if seen[c] {
+                    t.Errorf("candidatesFor(%q): %s повторяется", tt.goos, c)
+                }
+                seen[c] = true
+            }
+        })
+    }
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+    "bytes"
+    "io"
+    "os"
+    "os/exec"
+    "sync"
+    "time"
+)
+
+const (
+    defaultReadyFileName = "bot.ready"
+    readyFileEnvVar      = "EGAIS_READY_FILE"
+    readyPollInterval    = 250 * time.Millisecond
+)
+
+// readinessProbe ждёт от бота сигнала готовности: появления файла file или
+// строки marker в stdout. Бот, не приславший сигнал за timeout, считается
+// зависшим при старте.
+type readinessProbe struct {
+    file    string
+    marker  string
+    timeout time.Duration
+}
+
+// watch готовит cmd к наблюдению до запуска: удаляет файл готовности от
+// прошлого запуска и при необходимости перехватывает stdout. Канал
+// закрывается, когда бот сообщил о готовности; stop завершает наблюдение.
+func (p *readinessProbe) watch(cmd *exec.Cmd) (ready <-chan struct{}, stop func()) {
+    ch := make(chan struct{})
+    var once sync.Once
+    notify := func() { once.Do(func() { close(ch) }) }
+
+    if p.marker != "" {
+        cmd.Stdout = &markerWriter{w: cmd.Stdout, marker: []byte(p.marker), found: notify}
+    }
+    quit := make(chan struct{})
+    if p.file != "" {
+        os.Remove(p.file)
+        go func() {
+            ticker := time.NewTicker(readyPollInterval)
+            defer ticker.Stop()
+            for {
+                select {
+                case <-quit:
+                    return
+                case <-ticker.C:
+                    if _, err := os.Stat(p.file); err == nil {
+                        notify()
+                        return
+                    }
+                }
+            }
+        }()
+    }
+    return ch, func() { close(quit) }
+}
+
+// markerWriter передаёт вывод дальше без изменений и вызывает found, как
+// только встречает строку, содержащую marker.
+type markerWriter struct {
+    w      io.Writer
+    marker []byte
+    found  func()
+    line   []byte
+    done   bool
+}
+
+func (m *markerWriter) Write(p []byte) (int, error) {
+    if !m.done {
+        m.line = append(m.line, p...)
+        for {
+            i := bytes.IndexByte(m.line, '\n')
+            if i < 0 {
+                break
+            }
+            if bytes.Contains(m.line[:i], m.marker) {
+                m.done = true
+                m.found()
+                break
+            }
+            m.line = m.line[i+1:]
+        }
+        if m.done {
+            m.line = nil
+        } else if len(m.line) > 4096 {
+            m.line = m.line[len(m.line)-len(m.marker):]
+        }
+    }
+    if m.w == nil {
+        return len(p), nil
+    }
+    return m.w.Write(p)
+}
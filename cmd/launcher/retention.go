@@ -0,0 +1,77 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "sort"
+    "time"
+)
+
+const (
+    defaultLogRetentionDays = 14
+    defaultLogsMaxSizeMB    = 200
+)
+
+// activeLogNames — файлы, в которые лаунчер пишет прямо сейчас; очистка
+// их не трогает, старые данные из них уходят через ротацию.
+var activeLogNames = map[string]bool{
+    launcherLogName:  true,
+    botStdoutLogName: true,
+    botStderrLogName: true,
+}
+
+// cleanupLogs удаляет из logs файлы старше maxAge, а затем самые старые
+// файлы, пока общий размер каталога не станет меньше maxSize. Нулевые
+// значения отключают соответствующее ограничение.
+func cleanupLogs(baseDir string, maxAge time.Duration, maxSize int64) {
+    dir := filepath.Join(baseDir, logDirName)
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        return
+    }
+    type logFile struct {
+        path    string
+        size    int64
+        modTime time.Time
+    }
+    var files []logFile
+    var total int64
+    for _, e := range entries {
+        info, err := e.Info()
+        if err != nil || !info.Mode().IsRegular() {
+            continue
+        }
+        total += info.Size()
+        if activeLogNames[e.Name()] {
+            continue
+        }
+        files = append(files, logFile{path: filepath.Join(dir, e.Name()), size: info.Size(), modTime: info.ModTime()})
+    }
+    sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+    var removed int
+    var reclaimed int64
+    remove := func(f logFile) {
+        if err := os.Remove(f.path); err != nil {
+            logger.Printf("Не удалось удалить старый журнал %s: %v", f.path, err)
+            return
+        }
+        removed++
+        reclaimed += f.size
+        total -= f.size
+    }
+    cutoff := time.Now().Add(-maxAge)
+    for len(files) > 0 {
+        f := files[0]
+        expired := maxAge > 0 && f.modTime.Before(cutoff)
+        oversize := maxSize > 0 && total > maxSize
+        if !expired && !oversize {
+            break
+        }
+        remove(f)
+        files = files[1:]
+    }
+    if removed > 0 {
+        logger.Infof("Очистка журналов: удалено файлов %d, освобождено %.1f МБ", removed, float64(reclaimed)/(1<<20))
+    }
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+    "fmt"
+    "time"
+)
+
+const scheduleCheckInterval = 30 * time.Second
+
+// dailyTime — местное время суток для ежедневного перезапуска.
+type dailyTime struct {
+    hour, minute int
+}
+
+func parseDailyTime(s string) (dailyTime, error) {
+    t, err := time.Parse("15:04", s)
+    if err != nil {
+        return dailyTime{}, fmt.Errorf("ожидается время ЧЧ:ММ, получено %q", s)
+    }
+    return dailyTime{hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+func (d dailyTime) String() string {
+    return fmt.Sprintf("%02d:%02d", d.hour, d.minute)
+}
+
+// next возвращает ближайший после now момент d по местному времени. Дата
+// собирается заново через time.Date, поэтому переход на летнее время не
+// сдвигает расписание: несуществующее время (02:30 в ночь перевода
+// вперёд) нормализуется на час позже.
+func (d dailyTime) next(now time.Time) time.Time {
+    y, m, day := now.Date()
+    t := time.Date(y, m, day, d.hour, d.minute, 0, 0, now.Location())
+    if !t.After(now) {
+        t = time.Date(y, m, day+1, d.hour, d.minute, 0, 0, now.Location())
+    }
+    return t
+}
+
+// runDailyRestart каждый день в at просит супервизор корректно
+// перезапустить бота. Время сверяется по настенным часам раз в
+// scheduleCheckInterval, поэтому перевод часов и сон компьютера не
+// сбивают расписание. Если бот в этот момент не работает, перезапуск
+// пропускается до следующего дня.
+func runDailyRestart(at dailyTime, ctl *controller, stop <-chan struct{}) {
+    ticker := time.NewTicker(scheduleCheckInterval)
+    defer ticker.Stop()
+    next := at.next(time.Now())
+    logger.Infof("Следующий плановый перезапуск: %s", next.Format("02.01.2006 15:04"))
+    for {
+        select {
+        case <-stop:
+            return
+        case <-ticker.C:
+        }
+        now := time.Now().Round(0)
+        if now.Before(next) {
+            continue
+        }
+        if snap := ctl.status.snapshot(); snap.State == stateRunning {
+            logger.Event("scheduled_restart").Printf("Плановый перезапуск бота (restart_at %s)", at)
+            ctl.requestRestart("плановый перезапуск в " + at.String())
+        } else {
+            logger.Event("scheduled_restart_skipped").Printf("Плановый перезапуск в %s пропущен: бот не работает (%s)", at, snap.State)
+        }
+        next = at.next(now)
+        logger.Infof("Следующий плановый перезапуск: %s", next.Format("02.01.2006 15:04"))
+    }
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseDailyTime(t *testing.T) {
+    tests := []struct {
+        s       string
+        want    dailyTime
+        wantErr bool
+    }{
+        {s: "03:30", want: dailyTime{3, 30}},
+        {s: "00:00", want: dailyTime{0, 0}},
+        {s: "23:59", want: dailyTime{23, 59}},
+        {s: "24:00", wantErr: true},
+        {s: "12:60", wantErr: true},
+        {s: "3.30", wantErr: true},
+        {s: "", wantErr: true},
+    }
+    for _, tt := range tests {
+        got, err := parseDailyTime(tt.s)
+        if (err != nil) != tt.wantErr || got != tt.want {
+            t.Errorf("parseDailyTime(%q) = %v, %v; ожидалось %v, ошибка %v", tt.s, got, err, tt.want, tt.wantErr)
+        }
+    }
+}
+
+func TestDailyTimeNext(t *testing.T) {
+    moscow := time.FixedZone("MSK", 3*60*60)
+    at := dailyTime{hour: 3, minute: 30}
+    tests := []struct {
+        name string
+        now  time.Time
+        want time.Time
+    }{
+        {"до времени", time.Date(2026, 3, 10, 1, 0, 0, 0, moscow), time.Date(2026, 3, 10, 3, 30, 0, 0, moscow)},
+        {"ровно во время", time.Date(2026, 3, 10, 3, 30, 0, 0, moscow), time.Date(2026, 3, 11, 3, 30, 0, 0, moscow)},
+        {"после времени", time.Date(2026, 3, 10, 12, 0, 0, 0, moscow), time.Date(2026, 3, 11, 3, 30, 0, 0, moscow)},
+        {"конец месяца", time.Date(2026, 3, 31, 23, 0, 0, 0, moscow), time.Date(2026, 4, 1, 3, 30, 0, 0, moscow)},
+        {"конец года", time.Date(2026, 12, 31, 4, 0, 0, 0, moscow), time.Date(2027, 1, 1, 3, 30, 0, 0, moscow)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := at.next(tt.now); !got.Equal(tt.want) {
+                t.Errorf("next(%v) = %v, ожидалось %v", tt.now, got, tt.want)
+            }
+        })
+    }
+}
+
+// TestDailyTimeNextDST проверяет, что переход на летнее время не сдвигает
+// расписание: на следующий день перезапуск снова в 03:30 по местному времени.
+func TestDailyTimeNextDST(t *testing.T) {
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Skipf("нет базы часовых поясов: %v", err)
+    }
+    at := dailyTime{hour: 3, minute: 30}
+    // В Европе в ночь на 29.03.2026 часы переводятся с 02:00 на 03:00.
+    now := time.Date(2026, 3, 28, 12, 0, 0, 0, berlin)
+    got := at.next(now)
+    if got.Hour() != 3 || got.Minute() != 30 || got.Day() != 29 {
+        t.Errorf("next(%v) = %v, ожидалось 29.03 03:30", now, got)
+    }
+    if got.Sub(now) != 14*time.Hour+30*time.Minute {
+        t.Errorf("до перезапуска %v, ожидалось 14h30m (с учётом перевода часов)", got.Sub(now))
+    }
+}
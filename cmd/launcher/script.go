@@ -0,0 +1,93 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+const (
+    scriptEnvVar   = "EGAIS_BOT_SCRIPT"
+    appScriptName  = "main.py"
+    defaultAppName = "bot_app"
+)
+
+var errUnknownApp = errors.New("неизвестное приложение")
+
+// resolveScriptPath выбирает скрипт бота по приоритету: --script, --app,
+// переменная EGAIS_BOT_SCRIPT, script_path из launcher.json, затем путь по
+// умолчанию. Как и для остальных параметров, флаги важнее окружения.
+// Пути из --script и переменной окружения должны лежать внутри каталога
+// установки, если не указан --allow-external-script.
+func resolveScriptPath(baseDir string, opts *options, cfg *Config) (string, error) {
+    if opts.script == "" && opts.app != "" {
+        return resolveAppScript(baseDir, opts.app)
+    }
+    override, source := opts.script, "--script"
+    if override == "" {
+        override, source = os.Getenv(scriptEnvVar), scriptEnvVar
+    }
+    if override == "" {
+        if cfg.ScriptPath != "" {
+            return resolvePath(baseDir, cfg.ScriptPath), nil
+        }
+        return filepath.Join(baseDir, defaultScriptRel), nil
+    }
+
+    path := resolvePath(baseDir, override)
+    if !opts.allowExternalScript && !isInsideDir(baseDir, path) {
+        return "", fmt.Errorf("скрипт %s из %s находится вне каталога установки %s (используйте --allow-external-script)", path, source, baseDir)
+    }
+    return path, nil
+}
+
+func isInsideDir(dir, path string) bool {
+    if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+        dir = resolved
+    }
+    if resolved, err := filepath.EvalSymlinks(path); err == nil {
+        path = resolved
+    }
+    rel, err := filepath.Rel(dir, path)
+    if err != nil {
+        return false
+    }
+    return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// resolveAppScript возвращает <app>/main.py в каталоге установки. Для
+// неизвестного имени ошибка перечисляет доступные приложения.
+func resolveAppScript(baseDir, app string) (string, error) {
+    if app != filepath.Base(app) || app == "." || app == ".." {
+        return "", fmt.Errorf("%w %q: укажите имя папки без пути", errUnknownApp, app)
+    }
+    path := filepath.Join(baseDir, app, appScriptName)
+    if _, err := os.Stat(path); err == nil {
+        return path, nil
+    }
+    apps := listApps(baseDir)
+    if len(apps) == 0 {
+        return "", fmt.Errorf("%w %q: в %s нет папок с %s", errUnknownApp, app, baseDir, appScriptName)
+    }
+    return "", fmt.Errorf("%w %q, доступны: %s", errUnknownApp, app, strings.Join(apps, ", "))
+}
+
+// listApps перечисляет папки каталога установки, содержащие main.py.
+func listApps(baseDir string) []string {
+    entries, err := os.ReadDir(baseDir)
+    if err != nil {
+        return nil
+    }
+    var apps []string
+    for _, e := range entries {
+        if !e.IsDir() {
+            continue
+        }
+        if _, err := os.Stat(filepath.Join(baseDir, e.Name(), appScriptName)); err == nil {
+            apps = append(apps, e.Name())
+        }
+    }
+    return apps
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestResolveScriptPath(t *testing.T) {
+    base := t.TempDir()
+    for _, app := range []string{"bot_app", "inventory"} {
+        if err := os.MkdirAll(filepath.Join(base, app), 0o755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(filepath.Join(base, app, appScriptName), nil, 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    outside := filepath.Join(t.TempDir(), "main.py")
+
+    tests := []struct {
+        name     string
+        opts     options
+        env      string
+        cfg      Config
+        want     string
+        wantErr  error
+        anyError bool
+    }{
+        {name: "по умолчанию", want: filepath.Join(base, "bot_app", "main.py")},
+        {name: "script_path", cfg: Config{ScriptPath: "custom/run.py"}, want: filepath.Join(base, "custom", "run.py")},
+        {name: "переменная важнее script_path", env: "env/run.py", cfg: Config{ScriptPath: "custom/run.py"}, want: filepath.Join(base, "env", "run.py")},
+        {name: "--app важнее переменной", opts: options{app: "inventory"}, env: "env/run.py", want: filepath.Join(base, "inventory", "main.py")},
+        {name: "--script важнее --app и переменной", opts: options{script: "cli/run.py", app: "inventory"}, env: "env/run.py", want: filepath.Join(base, "cli", "run.py")},
+        {name: "неизвестное приложение", opts: options{app: "missing"}, wantErr: errUnknownApp},
+        {name: "--app с путём", opts: options{app: "../inventory"}, wantErr: errUnknownApp},
+        {name: "переменная вне каталога", env: outside, anyError: true},
+        {name: "переменная вне каталога с разрешением", opts: options{allowExternalScript: true}, env: outside, want: outside},
+        {name: "--script вне каталога", opts: options{script: outside}, anyError: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            t.Setenv(scriptEnvVar, tt.env)
+            got, err := resolveScriptPath(base, &tt.opts, &tt.cfg)
+            switch {
+            case tt.wantErr != nil:
+                if !errors.Is(err, tt.wantErr) {
+                    t.Errorf("resolveScriptPath() ошибка = %v, ожидалась %v", err, tt.wantErr)
+                }
+            case tt.anyError:
+                if err == nil {
+                    t.Errorf("resolveScriptPath() = %q, ожидалась ошибка", got)
+                }
+            case err != nil:
+                t.Errorf("resolveScriptPath() ошибка: %v", err)
+            case got != tt.want:
+                t.Errorf("resolveScriptPath() = %q, ожидалось %q", got, tt.want)
+            }
+        })
+    }
+}
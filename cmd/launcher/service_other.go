@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+func runServiceCommand(opts *options, args []string) int {
+    logger.Printf("Режим службы доступен только в Windows")
+    return exitUsage
+}
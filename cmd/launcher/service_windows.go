@@ -0,0 +1,185 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "time"
+
+    "golang.org/x/sys/windows"
+    "golang.org/x/sys/windows/svc"
+    "golang.org/x/sys/windows/svc/eventlog"
+    "golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+    serviceName        = "EGAISBotLauncher"
+    serviceDisplayName = "EGAIS DataMatrix Bot"
+    serviceDescription = "Запускает и перезапускает Python-бота ЕГАИС/DataMatrix."
+)
+
+func runServiceCommand(opts *options, args []string) int {
+    var err error
+    switch opts.service {
+    case "install":
+        err = installService(args)
+        if err == nil {
+            logger.Printf("Служба %s установлена", serviceName)
+        }
+    case "uninstall":
+        err = uninstallService()
+        if err == nil {
+            logger.Printf("Служба %s удалена", serviceName)
+        }
+    case "run":
+        err = runService(opts)
+    }
+    if err != nil {
+        if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+            logger.Printf("Недостаточно прав: запустите лаунчер от имени администратора (%v)", err)
+        } else {
+            logger.Printf("Ошибка службы %s: %v", serviceName, err)
+        }
+        return exitFailure
+    }
+    return exitOK
+}
+
+func installService(args []string) error {
+    exePath, err := os.Executable()
+    if err != nil {
+        return err
+    }
+    m, err := mgr.Connect()
+    if err != nil {
+        return err
+    }
+    defer m.Disconnect()
+
+    if s, err := m.OpenService(serviceName); err == nil {
+        s.Close()
+        return fmt.Errorf("служба %s уже существует", serviceName)
+    }
+
+    serviceArgs := append([]string{"--service", "run"}, args...)
+    s, err := m.CreateService(serviceName, exePath, mgr.Config{
+        DisplayName: serviceDisplayName,
+        Description: serviceDescription,
+        StartType:   mgr.StartAutomatic,
+    }, serviceArgs...)
+    if err != nil {
+        return err
+    }
+    defer s.Close()
+
+    recovery := []mgr.RecoveryAction{
+        {Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+        {Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+        {Type: mgr.ServiceRestart, Delay: 60 * time.Second},
+    }
+    if err := s.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+        logger.Printf("Предупреждение: не удалось настроить восстановление службы: %v", err)
+    }
+    if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+        logger.Printf("Предупреждение: не удалось настроить восстановление службы: %v", err)
+    }
+
+    if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+        logger.Printf("Предупреждение: не удалось зарегистрировать источник журнала событий: %v", err)
+    }
+    if err := installBotEventSource(); err != nil {
+        logger.Printf("Предупреждение: не удалось зарегистрировать источник журнала событий %s: %v", botEventSource, err)
+    }
+    return nil
+}
+
+func uninstallService() error {
+    m, err := mgr.Connect()
+    if err != nil {
+        return err
+    }
+    defer m.Disconnect()
+
+    s, err := m.OpenService(serviceName)
+    if err != nil {
+        return fmt.Errorf("служба %s не установлена: %w", serviceName, err)
+    }
+    defer s.Close()
+
+    if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+        s.Control(svc.Stop)
+    }
+    if err := s.Delete(); err != nil {
+        return err
+    }
+    if err := eventlog.Remove(serviceName); err != nil {
+        logger.Printf("Предупреждение: не удалось удалить источник журнала событий: %v", err)
+    }
+    return nil
+}
+
+func runService(opts *options) error {
+    isService, err := svc.IsWindowsService()
+    if err != nil {
+        return err
+    }
+    if !isService {
+        return errors.New("режим --service run предназначен для запуска диспетчером служб")
+    }
+
+    if events, err := eventlog.Open(serviceName); err == nil {
+        defer events.Close()
+        logger.setEventSink(events)
+        defer logger.setEventSink(nil)
+    }
+    return svc.Run(serviceName, &launcherService{opts: opts})
+}
+
+type launcherService struct {
+    opts *options
+}
+
+// Execute запускает бота так же, как интерактивный режим. Остановка службы
+// передаётся в супервизор как сигнал прерывания. Ненулевой код возвращается
+// как код ошибки службы, чтобы сработали действия восстановления.
+func (s *launcherService) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+    changes <- svc.Status{State: svc.StartPending}
+
+    signals := make(chan os.Signal, 1)
+    done := make(chan int, 1)
+    ctl := newController(signals)
+    go func() {
+        done <- launch(s.opts, ctl)
+    }()
+
+    accepts := svc.AcceptStop | svc.AcceptShutdown
+    changes <- svc.Status{State: svc.Running, Accepts: accepts}
+    for {
+        select {
+        case code := <-done:
+            if code != exitOK {
+                return true, uint32(code)
+            }
+            return false, 0
+        case req := <-requests:
+            switch req.Cmd {
+            case svc.Interrogate:
+                changes <- req.CurrentStatus
+            case svc.Stop, svc.Shutdown:
+                // До запуска бота launch ещё не прочитал shutdown_timeout_seconds.
+                wait := defaultShutdownTimeout
+                if s.opts.shutdownTimeout > 0 {
+                    wait = s.opts.shutdownTimeout
+                }
+                wait = ctl.stopTimeout(wait)
+                changes <- svc.Status{State: svc.StopPending, WaitHint: uint32((wait + 5*time.Second).Milliseconds())}
+                select {
+                case signals <- os.Interrupt:
+                default:
+                }
+                <-done
+                return false, 0
+            }
+        }
+    }
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package main
+
+import (
+    "context"
+    "os/exec"
+    "strings"
+)
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+    return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// quoteArg заключает аргумент в одинарные кавычки, если без них оболочка
+// разобрала бы его иначе.
+func quoteArg(arg string) string {
+    if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\$`*?[]{}()<>|&;#~!") {
+        return arg
+    }
+    return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
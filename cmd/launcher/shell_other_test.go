@@ -0,0 +1,43 @@
+//go:build !windows
+
+package main
+
+import (
+    "context"
+    "testing"
+)
+
+func TestQuoteArg(t *testing.T) {
+    tests := []struct {
+        arg  string
+        want string
+    }{
+        {"main.py", "main.py"},
+        {"", "''"},
+        {"/opt/EGAIS Bot/main.py", "'/opt/EGAIS Bot/main.py'"},
+        {"it's", `'it'\''s'`},
+        {"a&b", "'a&b'"},
+    }
+    for _, tt := range tests {
+        if got := quoteArg(tt.arg); got != tt.want {
+            t.Errorf("quoteArg(%q) = %s, ожидалось %s", tt.arg, got, tt.want)
+        }
+    }
+}
+
+// TestFormatCommandLineRoundTrip выполняет собранную командную строку
+// через shellCommand: printf должен получить аргумент в неизменном виде.
+func TestFormatCommandLineRoundTrip(t *testing.T) {
+    for _, tc := range quotingCases {
+        t.Run(tc.name, func(t *testing.T) {
+            line := formatCommandLine("printf", []string{"%s", tc.arg})
+            out, err := shellCommand(context.Background(), line).Output()
+            if err != nil {
+                t.Fatalf("%s: %v", line, err)
+            }
+            if string(out) != tc.arg {
+                t.Errorf("%s напечатал %q, ожидалось %q", line, out, tc.arg)
+            }
+        })
+    }
+}
@@ -0,0 +1,22 @@
+package main
+
+// quotingCases — аргументы, которые ломаются при неверном экранировании в
+// cmd.exe, CommandLineToArgvW или sh. Используются тестами обеих ОС.
+var quotingCases = []struct {
+    name string
+    arg  string
+}{
+    {"простой", "main.py"},
+    {"пустой", ""},
+    {"пробелы", `C:\Program Files\EGAIS Bot\bot_app\main.py`},
+    {"амперсанд", "ООО Рога & Копыта"},
+    {"каретка", "a^b"},
+    {"процент", "%APPDATA%\\bot"},
+    {"скобки", `C:\Program Files (x86)\Python312\python.exe`},
+    {"двойные кавычки", `--name="Бот ЕГАИС"`},
+    {"одинарные кавычки", "it's"},
+    {"черта в конце", `C:\EGAIS Bot\`},
+    {"черты в конце", `C:\EGAIS Bot\\`},
+    {"черта перед кавычкой", `a\"b`},
+    {"табуляция", "a\tb"},
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+    "context"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "syscall"
+)
+
+// shellCommand выполняет command через cmd.exe. Строка передаётся в
+// командную строку как есть: exec.Command экранирует кавычки как \",
+// чего cmd.exe не понимает, и хук вида "C:\Program Files\EGAIS Bot\x.bat"
+// ломается. С /S cmd.exe снимает только внешние кавычки, добавленные здесь,
+// а кавычки внутри command сохраняются.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+    comspec := os.Getenv("ComSpec")
+    if comspec == "" {
+        comspec = filepath.Join(os.Getenv("SystemRoot"), "System32", "cmd.exe")
+    }
+    cmd := exec.CommandContext(ctx, comspec)
+    cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: syscall.EscapeArg(comspec) + ` /S /C "` + command + `"`}
+    return cmd
+}
+
+// quoteArg заключает аргумент в кавычки по правилам CommandLineToArgvW,
+// которыми пользуются Python и exec.Command: кавычки внутри экранируются
+// обратной косой чертой, а черты перед ними удваиваются.
+func quoteArg(arg string) string {
+    return syscall.EscapeArg(arg)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "testing"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+// commandLineToArgv разбирает строку так же, как Python и другие программы
+// на C: функцией CommandLineToArgvW.
+func commandLineToArgv(t *testing.T, line string) []string {
+    t.Helper()
+    p, err := windows.UTF16PtrFromString(line)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var argc int32
+    argv, err := windows.CommandLineToArgv(p, &argc)
+    if err != nil {
+        t.Fatalf("CommandLineToArgv(%q): %v", line, err)
+    }
+    defer windows.LocalFree(windows.Handle(unsafe.Pointer(argv)))
+    args := make([]string, argc)
+    for i := range args {
+        args[i] = windows.UTF16PtrToString(&argv[i][0])
+    }
+    return args
+}
+
+func TestQuoteArg(t *testing.T) {
+    tests := []struct {
+        arg  string
+        want string
+    }{
+        {"main.py", "main.py"},
+        {"", `""`},
+        {`C:\Program Files\EGAIS Bot\main.py`, `"C:\Program Files\EGAIS Bot\main.py"`},
+        {`C:\EGAIS Bot\`, `"C:\EGAIS Bot\\"`},
+        {`a"b`, `a\"b`},
+    }
+    for _, tt := range tests {
+        if got := quoteArg(tt.arg); got != tt.want {
+            t.Errorf("quoteArg(%q) = %s, ожидалось %s", tt.arg, got, tt.want)
+        }
+    }
+}
+
+func TestQuoteArgRoundTrip(t *testing.T) {
+    for _, tc := range quotingCases {
+        t.Run(tc.name, func(t *testing.T) {
+            // Первый элемент CommandLineToArgvW разбирает по особым правилам
+            // (как имя программы), поэтому перед аргументом стоит заглушка.
+            got := commandLineToArgv(t, "x "+quoteArg(tc.arg))
+            if len(got) != 2 || got[1] != tc.arg {
+                t.Errorf("quoteArg(%q) = %s разбирается в %q", tc.arg, quoteArg(tc.arg), got)
+            }
+        })
+    }
+}
+
+func TestFormatCommandLineRoundTrip(t *testing.T) {
+    name := `C:\Program Files (x86)\Python312\python.exe`
+    args := make([]string, 0, len(quotingCases))
+    for _, tc := range quotingCases {
+        args = append(args, tc.arg)
+    }
+    line := formatCommandLine(name, args)
+    got := commandLineToArgv(t, line)
+    if want := append([]string{name}, args...); !slices.Equal(got, want) {
+        t.Errorf("formatCommandLine = %s\nразбирается в %q\nожидалось      %q", line, got, want)
+    }
+}
+
+// TestShellCommandQuotedPath запускает хук из каталога с пробелами и
+// символами, особыми для cmd.exe: кавычки в команде должны дойти до
+// cmd.exe нетронутыми. Аргумент латиницей: echo печатает в кодовой
+// странице консоли, а не в UTF-8.
+func TestShellCommandQuotedPath(t *testing.T) {
+    dir := filepath.Join(t.TempDir(), "EGAIS Bot & (x64) ^1")
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        t.Fatal(err)
+    }
+    hook := filepath.Join(dir, "hook.bat")
+    if err := os.WriteFile(hook, []byte("@echo off\r\necho %~1\r\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    out, err := shellCommand(context.Background(), quoteArg(hook)+" "+quoteArg("arg with space")).CombinedOutput()
+    if err != nil {
+        t.Fatalf("хук: %v\n%s", err, out)
+    }
+    if got := strings.TrimSpace(string(out)); got != "arg with space" {
+        t.Errorf("хук напечатал %q", got)
+    }
+}
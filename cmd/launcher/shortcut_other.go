@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+func runShortcutCommand(install bool, locations string, args []string) int {
+    logger.Printf("Ярлыки на рабочем столе и в меню «Пуск» создаются только в Windows")
+    return exitUsage
+}
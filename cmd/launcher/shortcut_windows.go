@@ -0,0 +1,200 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "syscall"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+const (
+    shortcutName     = serviceDisplayName + ".lnk"
+    shortcutIconName = "launcher.ico"
+)
+
+var (
+    ole32                = syscall.NewLazyDLL("ole32.dll")
+    procCoCreateInstance = ole32.NewProc("CoCreateInstance")
+
+    clsidShellLink  = windows.GUID{Data1: 0x00021401, Data4: [8]byte{0xc0, 0, 0, 0, 0, 0, 0, 0x46}}
+    iidIShellLinkW  = windows.GUID{Data1: 0x000214f9, Data4: [8]byte{0xc0, 0, 0, 0, 0, 0, 0, 0x46}}
+    iidIPersistFile = windows.GUID{Data1: 0x0000010b, Data4: [8]byte{0xc0, 0, 0, 0, 0, 0, 0, 0x46}}
+)
+
+// Номера методов в таблицах IShellLinkW и IPersistFile (shobjidl.h, objidl.h).
+const (
+    vtblQueryInterface  = 0
+    vtblRelease         = 2
+    vtblSetDescription  = 7
+    vtblSetWorkingDir   = 9
+    vtblSetArguments    = 11
+    vtblSetIconLocation = 17
+    vtblSetPath         = 20
+    vtblPersistSave     = 6
+)
+
+// runShortcutCommand создаёт ярлыки лаунчера на рабочем столе и в меню
+// «Пуск» текущего пользователя или удаляет их. Ярлык запускает лаунчер с
+// переданными аргументами из его каталога; значок берётся из того же
+// рисунка, что и в области уведомлений.
+func runShortcutCommand(install bool, locations string, args []string) int {
+    exePath, err := os.Executable()
+    if err != nil {
+        logger.Printf("Не удалось определить путь к exe: %v", err)
+        return exitFailure
+    }
+    iconPath := shortcutIconPath()
+    if install && iconPath != "" {
+        if err := writeShortcutIcon(iconPath); err != nil {
+            logger.Printf("Предупреждение: не удалось записать значок %s, у ярлыка будет значок exe: %v", iconPath, err)
+            iconPath = ""
+        }
+    }
+
+    code := exitOK
+    for _, loc := range strings.Split(locations, ",") {
+        dir, err := shortcutDir(strings.TrimSpace(loc))
+        if err != nil {
+            logger.Printf("Не удалось определить каталог ярлыка %s: %v", loc, err)
+            code = exitFailure
+            continue
+        }
+        path := filepath.Join(dir, shortcutName)
+        if !install {
+            if err := os.Remove(path); err == nil {
+                logger.Printf("Ярлык удалён: %s", path)
+            } else if !errors.Is(err, os.ErrNotExist) {
+                logger.Printf("Не удалось удалить ярлык %s: %v", path, err)
+                code = exitFailure
+            }
+            continue
+        }
+        if err := createShortcut(path, exePath, args, filepath.Dir(exePath), iconPath); err != nil {
+            logger.Printf("Не удалось создать ярлык %s: %v", path, err)
+            code = exitFailure
+            continue
+        }
+        logger.Printf("Ярлык создан: %s", path)
+    }
+    if !install && iconPath != "" {
+        os.Remove(iconPath)
+        os.Remove(filepath.Dir(iconPath))
+    }
+    return code
+}
+
+func shortcutDir(location string) (string, error) {
+    switch location {
+    case "desktop":
+        return windows.KnownFolderPath(windows.FOLDERID_Desktop, windows.KF_FLAG_DEFAULT)
+    case "start-menu":
+        return windows.KnownFolderPath(windows.FOLDERID_Programs, windows.KF_FLAG_DEFAULT)
+    }
+    return "", fmt.Errorf("неизвестное расположение %q", location)
+}
+
+// shortcutIconPath — значок кладётся в %LOCALAPPDATA%, а не рядом с exe:
+// каталог установки в Program Files обычно закрыт для записи.
+func shortcutIconPath() string {
+    dir, err := os.UserCacheDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(dir, "EGAISBot", shortcutIconName)
+}
+
+func writeShortcutIcon(path string) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    return os.WriteFile(path, trayIcon(trayColorRunning), 0o644)
+}
+
+// createShortcut сохраняет .lnk через COM-объект ShellLink. x/sys/windows
+// не описывает IShellLinkW, поэтому методы вызываются по таблице напрямую.
+func createShortcut(path, target string, args []string, workDir, iconPath string) error {
+    runtime.LockOSThread()
+    defer runtime.UnlockOSThread()
+    if err := windows.CoInitializeEx(0, windows.COINIT_APARTMENTTHREADED); err != nil && err != syscall.Errno(windows.S_FALSE) {
+        return fmt.Errorf("CoInitializeEx: %w", err)
+    }
+    defer windows.CoUninitialize()
+
+    var link unsafe.Pointer
+    r, _, _ := procCoCreateInstance.Call(
+        uintptr(unsafe.Pointer(&clsidShellLink)), 0, windows.CLSCTX_INPROC_SERVER,
+        uintptr(unsafe.Pointer(&iidIShellLinkW)), uintptr(unsafe.Pointer(&link)))
+    if err := hresultError("CoCreateInstance", r); err != nil {
+        return err
+    }
+    defer comRelease(link)
+
+    quoted := make([]string, len(args))
+    for i, a := range args {
+        quoted[i] = quoteArg(a)
+    }
+    for _, s := range []struct {
+        method int
+        name   string
+        value  string
+    }{
+        {vtblSetPath, "SetPath", target},
+        {vtblSetArguments, "SetArguments", strings.Join(quoted, " ")},
+        {vtblSetWorkingDir, "SetWorkingDirectory", workDir},
+        {vtblSetDescription, "SetDescription", "Запуск " + serviceDisplayName},
+    } {
+        if err := comCallString(link, s.method, s.name, s.value, 0); err != nil {
+            return err
+        }
+    }
+    if iconPath != "" {
+        if err := comCallString(link, vtblSetIconLocation, "SetIconLocation", iconPath, 0); err != nil {
+            return err
+        }
+    }
+
+    var persist unsafe.Pointer
+    r, _, _ = syscall.SyscallN(comMethod(link, vtblQueryInterface), uintptr(link),
+        uintptr(unsafe.Pointer(&iidIPersistFile)), uintptr(unsafe.Pointer(&persist)))
+    if err := hresultError("QueryInterface(IPersistFile)", r); err != nil {
+        return err
+    }
+    defer comRelease(persist)
+    return comCallString(persist, vtblPersistSave, "IPersistFile.Save", path, 1)
+}
+
+// comMethod возвращает адрес метода с номером method из таблицы объекта.
+// Указатели передаются прямо в syscall.SyscallN, чтобы сборщик мусора
+// не освободил и не переместил их во время вызова.
+func comMethod(obj unsafe.Pointer, method int) uintptr {
+    vtbl := *(*unsafe.Pointer)(obj)
+    return *(*uintptr)(unsafe.Add(vtbl, uintptr(method)*unsafe.Sizeof(uintptr(0))))
+}
+
+func comRelease(obj unsafe.Pointer) {
+    syscall.SyscallN(comMethod(obj, vtblRelease), uintptr(obj))
+}
+
+// comCallString вызывает метод вида (LPCWSTR, int): у SetIconLocation это
+// номер значка, у IPersistFile.Save — признак fRemember.
+func comCallString(obj unsafe.Pointer, method int, name, value string, extra uintptr) error {
+    p, err := windows.UTF16PtrFromString(value)
+    if err != nil {
+        return err
+    }
+    r, _, _ := syscall.SyscallN(comMethod(obj, method), uintptr(obj), uintptr(unsafe.Pointer(p)), extra)
+    return hresultError(name, r)
+}
+
+func hresultError(name string, r uintptr) error {
+    if int32(r) < 0 {
+        return fmt.Errorf("%s: HRESULT 0x%08X", name, uint32(r))
+    }
+    return nil
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+func runStartupCommand(register bool, args []string) int {
+    logger.Printf("Автозапуск через Планировщик заданий доступен только в Windows")
+    return exitUsage
+}
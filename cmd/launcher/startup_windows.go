@@ -0,0 +1,78 @@
+package main
+
+import (
+    "errors"
+    "os"
+    "os/exec"
+    "strings"
+
+    "golang.org/x/sys/windows"
+)
+
+const startupTaskName = "EGAISBotLauncher"
+
+// runStartupCommand создаёт или удаляет задачу Планировщика заданий,
+// которая запускает лаунчер при входе пользователя с переданными
+// аргументами. Повторная регистрация перезаписывает задачу.
+func runStartupCommand(register bool, args []string) int {
+    exePath, err := os.Executable()
+    if err != nil {
+        logger.Printf("Не удалось определить путь к exe: %v", err)
+        return exitFailure
+    }
+    exists := startupTaskExists()
+
+    if !register {
+        if !exists {
+            logger.Printf("Задача автозапуска %s не зарегистрирована", startupTaskName)
+            return exitOK
+        }
+        if out, err := schtasks("/Delete", "/TN", startupTaskName, "/F"); err != nil {
+            reportSchtasksError("удалить", out, err)
+            return exitFailure
+        }
+        logger.Printf("Задача автозапуска %s удалена", startupTaskName)
+        return exitOK
+    }
+
+    // Планировщик ограничивает /TR 261 символом, поэтому передаём
+    // только явно заданные аргументы.
+    command := formatCommandLine(exePath, args)
+    if len(command) > 261 {
+        logger.Printf("Командная строка задачи длиннее 261 символа, Планировщик заданий её не примет: %s", command)
+        return exitUsage
+    }
+    out, err := schtasks("/Create", "/TN", startupTaskName, "/TR", command, "/SC", "ONLOGON", "/RL", "LIMITED", "/F")
+    if err != nil {
+        reportSchtasksError("зарегистрировать", out, err)
+        return exitFailure
+    }
+    if exists {
+        logger.Printf("Задача автозапуска %s обновлена: %s", startupTaskName, command)
+    } else {
+        logger.Printf("Задача автозапуска %s создана: %s", startupTaskName, command)
+    }
+    return exitOK
+}
+
+func startupTaskExists() bool {
+    _, err := schtasks("/Query", "/TN", startupTaskName)
+    return err == nil
+}
+
+func schtasks(args ...string) (string, error) {
+    out, err := exec.Command("schtasks", args...).CombinedOutput()
+    return strings.TrimSpace(string(out)), err
+}
+
+// reportSchtasksError подсказывает про права администратора, если лаунчер
+// запущен без повышения: текст ошибки schtasks выводится в кодировке
+// консоли и не годится для разбора.
+func reportSchtasksError(action, out string, err error) {
+    var exitErr *exec.ExitError
+    if errors.As(err, &exitErr) && !windows.GetCurrentProcessToken().IsElevated() {
+        logger.Printf("Не удалось %s задачу автозапуска. Лаунчер запущен без прав администратора, а они обычно нужны для задачи «при входе»: запустите его от имени администратора (%s)", action, out)
+        return
+    }
+    logger.Printf("Не удалось %s задачу автозапуска: %v: %s", action, err, out)
+}
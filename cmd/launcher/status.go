@@ -0,0 +1,90 @@
+package main
+
+import (
+    "sync"
+    "time"
+)
+
+type botState string
+
+const (
+    stateStarting   botState = "starting"
+    stateRunning    botState = "running"
+    stateRestarting botState = "restarting"
+    stateStopped    botState = "stopped"
+    stateFailed     botState = "failed"
+)
+
+type statusSnapshot struct {
+    State        botState
+    PID          int
+    StartedAt    time.Time
+    Restarts     int
+    LastExitCode int
+}
+
+func (s statusSnapshot) uptime() time.Duration {
+    if s.State != stateRunning || s.StartedAt.IsZero() {
+        return 0
+    }
+    return time.Since(s.StartedAt)
+}
+
+// botStatus хранит текущее состояние процесса бота. Супервизор обновляет
+// его, а трей и другие наблюдатели читают снимок или подписываются на
+// изменения через subscribe.
+type botStatus struct {
+    mu        sync.Mutex
+    snap      statusSnapshot
+    listeners []func(statusSnapshot)
+}
+
+func newBotStatus() *botStatus {
+    return &botStatus{snap: statusSnapshot{State: stateStarting}}
+}
+
+func (b *botStatus) snapshot() statusSnapshot {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return b.snap
+}
+
+func (b *botStatus) subscribe(fn func(statusSnapshot)) {
+    b.mu.Lock()
+    b.listeners = append(b.listeners, fn)
+    b.mu.Unlock()
+}
+
+func (b *botStatus) update(fn func(*statusSnapshot)) {
+    b.mu.Lock()
+    fn(&b.snap)
+    snap := b.snap
+    listeners := append([]func(statusSnapshot){}, b.listeners...)
+    b.mu.Unlock()
+    for _, l := range listeners {
+        l(snap)
+    }
+}
+
+func (b *botStatus) started(pid int) {
+    b.update(func(s *statusSnapshot) {
+        s.State = stateRunning
+        s.PID = pid
+        s.StartedAt = time.Now()
+    })
+}
+
+func (b *botStatus) exited(state botState, code int) {
+    b.update(func(s *statusSnapshot) {
+        s.State = state
+        s.PID = 0
+        s.LastExitCode = code
+    })
+}
+
+func (b *botStatus) restarting(restarts int) {
+    b.update(func(s *statusSnapshot) {
+        s.State = stateRestarting
+        s.Restarts = restarts
+    })
+}
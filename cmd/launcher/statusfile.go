@@ -0,0 +1,72 @@
+package main
+
+import (
+    "encoding/json"
+    "time"
+)
+
+const statusFileName = "status.json"
+
+// statusFileData — содержимое status.json. Совокупное время работы
+// учитывает завершённые запуски бота; для работающего бота к нему прибавлено
+// время с bot_started_at на момент updated_at.
+type statusFileData struct {
+    LauncherStartedAt time.Time  `json:"launcher_started_at"`
+    UpdatedAt         time.Time  `json:"updated_at"`
+    State             botState   `json:"state"`
+    PID               int        `json:"pid,omitempty"`
+    BotStartedAt      *time.Time `json:"bot_started_at,omitempty"`
+    Restarts          int        `json:"restarts"`
+    LastExitCode      int        `json:"last_exit_code"`
+    LastCrashAt       *time.Time `json:"last_crash_at,omitempty"`
+    UptimeSeconds     int64      `json:"uptime_seconds"`
+}
+
+// trackStatusFile обновляет path при каждой смене состояния бота. Файл
+// перезаписывается атомарно, поэтому его можно читать в любой момент, и
+// остаётся после выхода лаунчера с последним состоянием.
+func trackStatusFile(path string, status *botStatus, launcherStarted time.Time) (final func()) {
+    data := statusFileData{LauncherStartedAt: launcherStarted, State: stateStarting}
+    var uptime time.Duration
+    var runningSince time.Time
+
+    write := func() {
+        data.UpdatedAt = time.Now()
+        total := uptime
+        if !runningSince.IsZero() {
+            total += data.UpdatedAt.Sub(runningSince)
+        }
+        data.UptimeSeconds = int64(total / time.Second)
+        out, _ := json.MarshalIndent(data, "", "  ")
+        if err := writeFileAtomic(path, append(out, '\n')); err != nil {
+            logger.Printf("Не удалось записать %s: %v", path, err)
+        }
+    }
+
+    status.subscribe(func(snap statusSnapshot) {
+        if snap.State == data.State && snap.PID == data.PID && snap.Restarts == data.Restarts {
+            return
+        }
+        if !runningSince.IsZero() && snap.State != stateRunning {
+            uptime += time.Since(runningSince)
+            runningSince = time.Time{}
+            data.BotStartedAt = nil
+            if snap.PID == 0 && (snap.State == stateRestarting || snap.State == stateFailed) {
+                crashed := time.Now()
+                data.LastCrashAt = &crashed
+            }
+        }
+        if snap.State == stateRunning && runningSince.IsZero() {
+            runningSince = snap.StartedAt
+            started := snap.StartedAt
+            data.BotStartedAt = &started
+        }
+        data.State = snap.State
+        data.PID = snap.PID
+        data.Restarts = snap.Restarts
+        data.LastExitCode = snap.LastExitCode
+        write()
+    })
+    write()
+    return write
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "os/exec"
+    "sync/atomic"
+    "time"
+)
+
+const (
+    defaultMaxRestarts     = 5
+    defaultShutdownTimeout = 10 * time.Second
+    minBackoff             = time.Second
+    maxBackoff             = 60 * time.Second
+    stableUptime           = 30 * time.Second
+)
+
+// controller передаёт супервизору внешние команды (сигналы остановки и
+// запросы перезапуска) и публикует состояние бота для трея и других
+// наблюдателей.
+type controller struct {
+    signals <-chan os.Signal
+    restart chan string
+    status  *botStatus
+    // console заменяет стандартные потоки для вывода бота в консоль,
+    // например панелью --dashboard; nil — stdout и stderr лаунчера.
+    console io.Writer
+    // shutdownTimeout — время на корректное завершение бота, выбранное
+    // launch с учётом shutdown_timeout_seconds; 0 — launch его ещё не выбрал.
+    shutdownTimeout *atomic.Int64
+}
+
+func newController(signals <-chan os.Signal) *controller {
+    return &controller{
+        signals:         signals,
+        restart:         make(chan string, 1),
+        status:          newBotStatus(),
+        shutdownTimeout: new(atomic.Int64),
+    }
+}
+
+// stopTimeout возвращает время на корректное завершение бота, которое
+// использует супервизор, или fallback, если бот ещё не запущен.
+func (c *controller) stopTimeout(fallback time.Duration) time.Duration {
+    if d := time.Duration(c.shutdownTimeout.Load()); d > 0 {
+        return d
+    }
+    return fallback
+}
+
+// requestRestart просит супервизор корректно перезапустить бота. Повторные
+// запросы, пока предыдущий не обработан, отбрасываются.
+func (c *controller) requestRestart(reason string) {
+    select {
+    case c.restart <- reason:
+    default:
+    }
+}
+
+type childOutcome int
+
+const (
+    childExited childOutcome = iota
+    childStopped
+    childRestartRequested
+    childNotReady
+)
+
+// restartAction — что делать, когда перезапуски исчерпаны.
+type restartAction string
+
+const (
+    // restartExit завершает лаунчер с кодом бота.
+    restartExit restartAction = "exit"
+    // restartNotifyAndWait показывает уведомление и ждёт ручного
+    // перезапуска из трея или остановки лаунчера.
+    restartNotifyAndWait restartAction = "notify-and-wait"
+    // restartBotOnly выдерживает паузу maxBackoff и начинает новую серию
+    // попыток, не завершая лаунчер.
+    restartBotOnly restartAction = "reboot-bot-only"
+)
+
+func parseRestartAction(s string) (restartAction, error) {
+    switch a := restartAction(s); a {
+    case "":
+        return restartExit, nil
+    case restartExit, restartNotifyAndWait, restartBotOnly:
+        return a, nil
+    }
+    return "", fmt.Errorf("неизвестное действие %q (допустимо: %s, %s, %s)", s, restartExit, restartNotifyAndWait, restartBotOnly)
+}
+
+// restartPolicy задаёт, сколько аварийных завершений подряд (или за окно
+// window, если оно задано) допускается и с какой паузой между попытками.
+type restartPolicy struct {
+    maxRestarts int
+    window      time.Duration
+    minBackoff  time.Duration
+    maxBackoff  time.Duration
+    action      restartAction
+}
+
+func defaultRestartPolicy() restartPolicy {
+    return restartPolicy{
+        maxRestarts: defaultMaxRestarts,
+        minBackoff:  minBackoff,
+        maxBackoff:  maxBackoff,
+        action:      restartExit,
+    }
+}
+
+// attempts возвращает номер предстоящей попытки перезапуска: число сбоев
+// в окне или число перезапусков подряд.
+func (p restartPolicy) attempts(consecutive int, crashes []time.Time) int {
+    if p.window > 0 {
+        return len(crashes)
+    }
+    return consecutive + 1
+}
+
+// recordCrash добавляет время сбоя и отбрасывает сбои старше окна.
+func (p restartPolicy) recordCrash(crashes []time.Time, now time.Time) []time.Time {
+    if p.window <= 0 {
+        return nil
+    }
+    kept := crashes[:0]
+    for _, t := range crashes {
+        if now.Sub(t) < p.window {
+            kept = append(kept, t)
+        }
+    }
+    return append(kept, now)
+}
+
+type supervisor struct {
+    newCmd          func() *exec.Cmd
+    policy          restartPolicy
+    shutdownTimeout time.Duration
+    ctl             *controller
+    interpreter     string
+    readiness       *readinessProbe
+    crash           *crashReporter
+}
+
+func (s *supervisor) run() int {
+    backoff := s.policy.minBackoff
+    restarts := 0
+    totalRestarts := 0
+    var crashes []time.Time
+    for {
+        started := time.Now()
+        logger.Event("bot_start").Interpreter(s.interpreter).Restarts(totalRestarts).Infof("Запуск бота (перезапусков подряд: %d)", restarts)
+        if s.crash != nil {
+            s.crash.tail.reset()
+        }
+        code, outcome, err := s.runChild(s.newCmd())
+        if err != nil {
+            logger.Event("bot_start_failed").Interpreter(s.interpreter).ExitCode(exitFailure).Notify().Printf("Ошибка запуска python скрипта: %v", err)
+            s.ctl.status.exited(stateFailed, exitFailure)
+            return exitFailure
+        }
+        switch outcome {
+        case childStopped:
+            s.ctl.status.exited(stateStopped, code)
+            return code
+        case childRestartRequested:
+            totalRestarts++
+            backoff = s.policy.minBackoff
+            restarts = 0
+            crashes = nil
+            s.ctl.status.restarting(totalRestarts)
+            continue
+        }
+        if code == 0 {
+            logger.Event("bot_exit").ExitCode(0).Restarts(totalRestarts).Infof("Бот завершился штатно, время работы %s", time.Since(started).Round(time.Second))
+            s.ctl.status.exited(stateStopped, 0)
+            return exitOK
+        }
+        if outcome == childExited {
+            logger.Event("bot_exit").ExitCode(code).Restarts(totalRestarts).Printf("Python скрипт завершился с кодом %d", code)
+            if s.crash != nil {
+                s.crash.write(code, started)
+            }
+        }
+        logger.Infof("Время работы бота: %s", time.Since(started).Round(time.Second))
+
+        if time.Since(started) >= stableUptime {
+            backoff = s.policy.minBackoff
+            restarts = 0
+        }
+        crashes = s.policy.recordCrash(crashes, time.Now())
+        attempt := s.policy.attempts(restarts, crashes)
+        if attempt > s.policy.maxRestarts {
+            if s.policy.window > 0 {
+                logger.Event("restart_exhausted").ExitCode(code).Restarts(totalRestarts).Notify().Printf("Аварийных завершений бота за %s: %d. Перезапуски прекращены.", s.policy.window, attempt)
+            } else {
+                logger.Event("restart_exhausted").ExitCode(code).Restarts(totalRestarts).Notify().Printf("Аварийных завершений бота подряд: %d. Перезапуски прекращены.", attempt)
+            }
+            s.ctl.status.exited(stateFailed, code)
+            if !s.waitAfterExhausted() {
+                if code > 0 {
+                    return code
+                }
+                return exitFailure
+            }
+            totalRestarts++
+            backoff = s.policy.minBackoff
+            restarts = 0
+            crashes = nil
+            s.ctl.status.restarting(totalRestarts)
+            continue
+        }
+
+        restarts++
+        totalRestarts++
+        s.ctl.status.exited(stateRestarting, code)
+        s.ctl.status.restarting(totalRestarts)
+        logger.Event("restart_scheduled").Restarts(totalRestarts).Printf("Перезапуск бота через %s (попытка %d из %d)", backoff, attempt, s.policy.maxRestarts)
+        select {
+        case sig := <-s.ctl.signals:
+            logger.Printf("Получен сигнал %v, перезапуск отменён", sig)
+            s.ctl.status.exited(stateStopped, code)
+            return exitOK
+        case reason := <-s.ctl.restart:
+            logger.Printf("Перезапуск бота без ожидания: %s", reason)
+        case <-time.After(backoff):
+        }
+        backoff *= 2
+        if backoff > s.policy.maxBackoff {
+            backoff = s.policy.maxBackoff
+        }
+    }
+}
+
+// waitAfterExhausted выполняет действие политики после исчерпания
+// перезапусков. Возвращает true, если нужно начать новую серию попыток.
+func (s *supervisor) waitAfterExhausted() bool {
+    var cooldown <-chan time.Time
+    switch s.policy.action {
+    case restartNotifyAndWait:
+        logger.Printf("Бот остановлен. Перезапустите его из меню трея или завершите лаунчер.")
+    case restartBotOnly:
+        logger.Printf("Новая серия перезапусков через %s", s.policy.maxBackoff)
+        cooldown = time.After(s.policy.maxBackoff)
+    default:
+        return false
+    }
+    select {
+    case sig := <-s.ctl.signals:
+        logger.Printf("Получен сигнал %v, лаунчер завершается", sig)
+        return false
+    case reason := <-s.ctl.restart:
+        logger.Printf("Перезапуск бота: %s", reason)
+    case <-cooldown:
+    }
+    return true
+}
+
+// runChild запускает процесс бота и ждёт его завершения. Если во время
+// работы пришёл сигнал остановки или запрос перезапуска, бот получает
+// прерывание и shutdownTimeout на корректное завершение, после чего
+// процесс убивается.
+func (s *supervisor) runChild(cmd *exec.Cmd) (int, childOutcome, error) {
+    select {
+    case sig := <-s.ctl.signals:
+        logger.Printf("Получен сигнал %v до запуска бота", sig)
+        return exitOK, childStopped, nil
+    default:
+    }
+
+    var ready <-chan struct{}
+    var readyTimeout <-chan time.Time
+    if s.readiness != nil {
+        var stopWatch func()
+        ready, stopWatch = s.readiness.watch(cmd)
+        defer stopWatch()
+        timer := time.NewTimer(s.readiness.timeout)
+        defer timer.Stop()
+        readyTimeout = timer.C
+    }
+
+    configureChild(cmd)
+    if err := cmd.Start(); err != nil {
+        return 0, childExited, err
+    }
+    s.ctl.status.started(cmd.Process.Pid)
+    done := make(chan error, 1)
+    go func() {
+        done <- cmd.Wait()
+    }()
+
+    for {
+        select {
+        case err := <-done:
+            code, err := childExitCode(err)
+            return code, childExited, err
+        case <-ready:
+            logger.Event("bot_ready").Infof("Бот сообщил о готовности")
+            ready, readyTimeout = nil, nil
+        case <-readyTimeout:
+            logger.Event("bot_not_ready").ExitCode(exitNotReady).Printf("Бот не сообщил о готовности за %s и будет остановлен", s.readiness.timeout)
+            s.stopChild(cmd, done)
+            return exitNotReady, childNotReady, nil
+        case sig := <-s.ctl.signals:
+            logger.Printf("Получен сигнал %v, остановка бота (ожидание до %s)", sig, s.shutdownTimeout)
+            if s.stopChild(cmd, done) {
+                return exitOK, childStopped, nil
+            }
+            return exitFailure, childStopped, nil
+        case reason := <-s.ctl.restart:
+            logger.Printf("Перезапуск бота: %s", reason)
+            s.stopChild(cmd, done)
+            return 0, childRestartRequested, nil
+        }
+    }
+}
+
+// stopChild прерывает бота и ждёт его завершения. Возвращает false, если
+// процесс пришлось убить по истечении shutdownTimeout.
+func (s *supervisor) stopChild(cmd *exec.Cmd, done <-chan error) bool {
+    if err := interruptProcess(cmd.Process); err != nil {
+        logger.Printf("Не удалось передать сигнал боту: %v", err)
+        cmd.Process.Kill()
+    }
+    select {
+    case err := <-done:
+        code, _ := childExitCode(err)
+        logger.Event("bot_stopped").ExitCode(code).Printf("Бот остановлен (код %d)", code)
+        return true
+    case <-time.After(s.shutdownTimeout):
+        logger.Printf("Бот не завершился за %s, процесс будет принудительно остановлен", s.shutdownTimeout)
+        cmd.Process.Kill()
+        <-done
+        return false
+    }
+}
+
+func childExitCode(err error) (int, error) {
+    if err == nil {
+        return 0, nil
+    }
+    var exitErr *exec.ExitError
+    if errors.As(err, &exitErr) {
+        if code := exitErr.ExitCode(); code != 0 {
+            return code, nil
+        }
+        return -1, nil
+    }
+    return 0, err
+}
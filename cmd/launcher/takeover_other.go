@@ -0,0 +1,17 @@
+//go:build !windows
+
+package main
+
+import (
+    "os"
+    "syscall"
+)
+
+// watchStopRequests не нужен: лаунчер и так останавливается по SIGTERM.
+func watchStopRequests(signals chan<- os.Signal) {}
+
+// requestLauncherStop просит лаунчер pid корректно остановить бота и
+// завершиться.
+func requestLauncherStop(pid int) error {
+    return syscall.Kill(pid, syscall.SIGTERM)
+}
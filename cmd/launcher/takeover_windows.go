@@ -0,0 +1,52 @@
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "golang.org/x/sys/windows"
+)
+
+// stopEventName — событие, по которому лаунчер с данным PID корректно
+// останавливается. Сигнал консоли другому лаунчеру не отправить: он
+// дошёл бы до всех процессов консоли, включая нас самих.
+func stopEventName(pid int) string {
+    return fmt.Sprintf(`Local\EGAISBotLauncher-stop-%d`, pid)
+}
+
+// watchStopRequests создаёт событие остановки этого лаунчера и при его
+// установке передаёт в signals os.Interrupt, как при Ctrl+C.
+func watchStopRequests(signals chan<- os.Signal) {
+    name, err := windows.UTF16PtrFromString(stopEventName(os.Getpid()))
+    if err != nil {
+        return
+    }
+    h, err := windows.CreateEvent(nil, 0, 0, name)
+    if err != nil {
+        logger.Infof("Не удалось создать событие остановки: %v", err)
+        return
+    }
+    go func() {
+        defer windows.CloseHandle(h)
+        if _, err := windows.WaitForSingleObject(h, windows.INFINITE); err != nil {
+            return
+        }
+        logger.Printf("Получен запрос остановки от другого экземпляра лаунчера (--takeover)")
+        signals <- os.Interrupt
+    }()
+}
+
+// requestLauncherStop просит лаунчер pid корректно остановить бота и
+// завершиться.
+func requestLauncherStop(pid int) error {
+    name, err := windows.UTF16PtrFromString(stopEventName(pid))
+    if err != nil {
+        return err
+    }
+    h, err := windows.OpenEvent(windows.EVENT_MODIFY_STATE, false, name)
+    if err != nil {
+        return err
+    }
+    defer windows.CloseHandle(h)
+    return windows.SetEvent(h)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+func runTray(opts *options, ctl *controller) int {
+    logger.Printf("Параметр --tray поддерживается только в Windows, запуск без значка в трее")
+    return launch(opts, ctl)
+}
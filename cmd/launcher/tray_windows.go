@@ -0,0 +1,201 @@
+package main
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "image"
+    "image/color"
+    "image/png"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "time"
+
+    "fyne.io/systray"
+)
+
+const trayRefreshInterval = 5 * time.Second
+
+var (
+    trayColorRunning    = color.RGBA{R: 0x2e, G: 0xa0, B: 0x43, A: 0xff}
+    trayColorRestarting = color.RGBA{R: 0xe0, G: 0xa8, B: 0x00, A: 0xff}
+    trayColorDown       = color.RGBA{R: 0xd0, G: 0x21, B: 0x21, A: 0xff}
+)
+
+// runTray запускает бота в фоне и показывает значок в области уведомлений.
+// systray.Run должен выполняться в главной горутине, поэтому launch
+// уходит в отдельную.
+func runTray(opts *options, ctl *controller) int {
+    stop := make(chan os.Signal, 1)
+    trayCtl := &controller{signals: mergeSignals(ctl.signals, stop), restart: ctl.restart, status: ctl.status, shutdownTimeout: ctl.shutdownTimeout}
+
+    done := make(chan int, 1)
+    go func() {
+        for {
+            code := launch(opts, trayCtl)
+            if code == exitOK {
+                done <- code
+                systray.Quit()
+                return
+            }
+            // После сбоя значок остаётся красным. Супервизор уже не читает
+            // запросы перезапуска, поэтому «Перезапустить бота» запускает
+            // launch заново; «Выход» завершает лаунчер с кодом сбоя.
+            trayCtl.status.update(func(s *statusSnapshot) {
+                s.State = stateFailed
+                s.LastExitCode = code
+            })
+            select {
+            case reason := <-trayCtl.restart:
+                logger.Printf("Повторный запуск бота после сбоя: %s", reason)
+                trayCtl.status.update(func(s *statusSnapshot) {
+                    s.State = stateStarting
+                })
+            case <-trayCtl.signals:
+                done <- code
+                systray.Quit()
+                return
+            }
+        }
+    }()
+
+    systray.Run(func() { setupTray(trayCtl, stop) }, nil)
+    return <-done
+}
+
+func mergeSignals(a <-chan os.Signal, b <-chan os.Signal) <-chan os.Signal {
+    out := make(chan os.Signal, 1)
+    go func() {
+        for {
+            select {
+            case sig := <-a:
+                out <- sig
+            case sig := <-b:
+                out <- sig
+            }
+        }
+    }()
+    return out
+}
+
+func setupTray(ctl *controller, stop chan<- os.Signal) {
+    systray.SetTitle("EGAIS бот")
+    statusItem := systray.AddMenuItem("Состояние: запуск", "Текущее состояние бота")
+    statusItem.Disable()
+    systray.AddSeparator()
+    restartItem := systray.AddMenuItem("Перезапустить бота", "Корректно остановить и снова запустить бота")
+    logsItem := systray.AddMenuItem("Открыть папку журналов", "")
+    systray.AddSeparator()
+    quitItem := systray.AddMenuItem("Выход", "Остановить бота и закрыть лаунчер")
+
+    icons := map[botState][]byte{}
+    refresh := func(snap statusSnapshot) {
+        text := traySummary(snap)
+        statusItem.SetTitle("Состояние: " + text)
+        systray.SetTooltip("EGAIS бот: " + text)
+        icon, ok := icons[snap.State]
+        if !ok {
+            icon = trayIcon(trayStateColor(snap.State))
+            icons[snap.State] = icon
+        }
+        systray.SetIcon(icon)
+    }
+    refresh(ctl.status.snapshot())
+
+    updates := make(chan statusSnapshot, 1)
+    ctl.status.subscribe(func(snap statusSnapshot) {
+        select {
+        case <-updates:
+        default:
+        }
+        updates <- snap
+    })
+
+    go func() {
+        ticker := time.NewTicker(trayRefreshInterval)
+        defer ticker.Stop()
+        for {
+            select {
+            case snap := <-updates:
+                refresh(snap)
+            case <-ticker.C:
+                refresh(ctl.status.snapshot())
+            case <-restartItem.ClickedCh:
+                ctl.requestRestart("команда из меню трея")
+            case <-logsItem.ClickedCh:
+                openLogFolder()
+            case <-quitItem.ClickedCh:
+                select {
+                case stop <- os.Interrupt:
+                default:
+                }
+                systray.Quit()
+                return
+            }
+        }
+    }()
+}
+
+func traySummary(snap statusSnapshot) string {
+    switch snap.State {
+    case stateRunning:
+        return fmt.Sprintf("работает, PID %d, %s", snap.PID, snap.uptime().Round(time.Second))
+    case stateRestarting:
+        return fmt.Sprintf("перезапуск (код %d, перезапусков %d)", snap.LastExitCode, snap.Restarts)
+    case stateFailed:
+        return fmt.Sprintf("остановлен после сбоя (код %d)", snap.LastExitCode)
+    case stateStopped:
+        return "остановлен"
+    default:
+        return "запуск"
+    }
+}
+
+func trayStateColor(state botState) color.Color {
+    switch state {
+    case stateRunning:
+        return trayColorRunning
+    case stateRestarting, stateStarting:
+        return trayColorRestarting
+    default:
+        return trayColorDown
+    }
+}
+
+func openLogFolder() {
+    exePath, err := os.Executable()
+    if err != nil {
+        return
+    }
+    dir := filepath.Join(filepath.Dir(exePath), logDirName)
+    if err := exec.Command("explorer", dir).Start(); err != nil {
+        logger.Printf("Не удалось открыть папку журналов: %v", err)
+    }
+}
+
+// trayIcon рисует круглый значок 32×32 заданного цвета и упаковывает
+// его в ICO с PNG внутри, который понимает Windows Vista и новее.
+func trayIcon(c color.Color) []byte {
+    const size = 32
+    img := image.NewRGBA(image.Rect(0, 0, size, size))
+    center, radius := float64(size-1)/2, float64(size)/2-2
+    for y := 0; y < size; y++ {
+        for x := 0; x < size; x++ {
+            dx, dy := float64(x)-center, float64(y)-center
+            if dx*dx+dy*dy <= radius*radius {
+                img.Set(x, y, c)
+            }
+        }
+    }
+    var pngData bytes.Buffer
+    png.Encode(&pngData, img)
+
+    var ico bytes.Buffer
+    binary.Write(&ico, binary.LittleEndian, [3]uint16{0, 1, 1})
+    ico.Write([]byte{size, size, 0, 0})
+    binary.Write(&ico, binary.LittleEndian, [2]uint16{1, 32})
+    binary.Write(&ico, binary.LittleEndian, [2]uint32{uint32(pngData.Len()), 22})
+    ico.Write(pngData.Bytes())
+    return ico.Bytes()
+}
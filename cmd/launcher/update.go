@@ -0,0 +1,318 @@
+package main
+
+import (
+    "crypto/ed25519"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    updateDirName         = "update"
+    updateStagedDirName   = "staged"
+    updateBackupDirName   = "backup"
+    updateCompleteMarker  = ".complete"
+    updateInstalledName   = "installed_version"
+    updateManifestTimeout = 30 * time.Second
+)
+
+// updateVersionPattern — допустимые версии пакета: версия попадает в имена
+// файлов в update, поэтому разделители каталогов и ".." в ней запрещены.
+var updateVersionPattern = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+){0,3}(-[0-9A-Za-z]+(\.[0-9A-Za-z]+)*)?$`)
+
+// updateManifest — файл, опубликованный по update_manifest_url. Пакет —
+// zip-архив каталога установки (bot_app, лаунчер и т. п.), его содержимое
+// накладывается поверх каталога лаунчера при следующем запуске.
+// Signature — подпись Ed25519 (base64) строк version, url и sha256, каждая
+// с переводом строки.
+type updateManifest struct {
+    Version   string `json:"version"`
+    URL       string `json:"url"`
+    SHA256    string `json:"sha256"`
+    Signature string `json:"signature"`
+}
+
+// checkForUpdate сравнивает версию из манифеста с версией лаунчера и при
+// наличии более новой скачивает и распаковывает пакет в update\staged.
+// Любая ошибка только журналируется: текущая установка не меняется.
+func checkForUpdate(baseDir, manifestURL string) {
+    if version == "dev" {
+        logger.Infof("Проверка обновлений пропущена: версия лаунчера не задана при сборке")
+        return
+    }
+    // Без открытого ключа подлинность манифеста не проверить, а
+    // неподписанному манифесту верить нельзя: любой, кто ответит на
+    // update_manifest_url, разослал бы свой пакет по всем кассам.
+    pub, err := publicKey()
+    if err != nil {
+        logger.Printf("Обновления отключены: %v", err)
+        return
+    }
+    manifest, err := fetchUpdateManifest(manifestURL, pub)
+    if err != nil {
+        logger.Printf("Не удалось проверить обновления: %v", err)
+        return
+    }
+    current := installedVersion(baseDir)
+    if compareVersions(manifest.Version, current) <= 0 {
+        logger.Infof("Обновлений нет: доступна версия %s, установлена %s", manifest.Version, current)
+        return
+    }
+
+    stagedDir := filepath.Join(baseDir, updateDirName, updateStagedDirName)
+    if staged, err := os.ReadFile(filepath.Join(stagedDir, updateCompleteMarker)); err == nil && strings.TrimSpace(string(staged)) == manifest.Version {
+        logger.Infof("Обновление %s уже подготовлено и будет установлено при следующем запуске", manifest.Version)
+        return
+    }
+
+    logger.Event("update_found").Printf("Доступно обновление %s (установлена %s), загрузка", manifest.Version, current)
+    if err := stageUpdate(baseDir, manifest); err != nil {
+        logger.Printf("Не удалось подготовить обновление %s: %v", manifest.Version, err)
+        return
+    }
+    logger.Event("update_staged").Printf("Обновление %s подготовлено и будет установлено при следующем запуске", manifest.Version)
+}
+
+// installedVersion — большая из версий лаунчера и последнего установленного
+// пакета: пакет может обновлять только бота, не меняя exe.
+func installedVersion(baseDir string) string {
+    data, err := os.ReadFile(filepath.Join(baseDir, updateDirName, updateInstalledName))
+    if err != nil {
+        return version
+    }
+    if pkg := strings.TrimSpace(string(data)); compareVersions(pkg, version) > 0 {
+        return pkg
+    }
+    return version
+}
+
+func fetchUpdateManifest(url string, pub ed25519.PublicKey) (*updateManifest, error) {
+    client := &http.Client{Timeout: updateManifestTimeout}
+    resp, err := client.Get(url)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("%s: %s", url, resp.Status)
+    }
+    var manifest updateManifest
+    if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&manifest); err != nil {
+        return nil, fmt.Errorf("разбор манифеста %s: %w", url, err)
+    }
+    if manifest.Version == "" || manifest.URL == "" || manifest.SHA256 == "" {
+        return nil, fmt.Errorf("в манифесте %s должны быть заданы version, url и sha256", url)
+    }
+    if !updateVersionPattern.MatchString(manifest.Version) {
+        return nil, fmt.Errorf("в манифесте %s недопустимая версия %q", url, manifest.Version)
+    }
+    if err := manifest.verify(pub); err != nil {
+        return nil, fmt.Errorf("манифест %s: %w", url, err)
+    }
+    return &manifest, nil
+}
+
+// verify проверяет подпись манифеста. SHA-256 пакета приходит вместе с его
+// адресом, поэтому без подписи он защищает только от повреждения при
+// загрузке, а не от подмены манифеста; неподписанный манифест отклоняется.
+func (m *updateManifest) verify(pub ed25519.PublicKey) error {
+    if m.Signature == "" {
+        return errors.New("нет подписи (signature)")
+    }
+    return verifySignature(pub, m.signedData(), m.Signature)
+}
+
+// signedData — подписываемая часть манифеста.
+func (m *updateManifest) signedData() []byte {
+    return []byte(m.Version + "\n" + m.URL + "\n" + m.SHA256 + "\n")
+}
+
+func stageUpdate(baseDir string, manifest *updateManifest) error {
+    updateDir := filepath.Join(baseDir, updateDirName)
+    if err := os.MkdirAll(updateDir, 0o755); err != nil {
+        return err
+    }
+    archive := filepath.Join(updateDir, "package-"+manifest.Version+".zip")
+    if err := downloadVerified(manifest.URL, archive, strings.ToLower(manifest.SHA256), nil); err != nil {
+        return err
+    }
+    defer os.Remove(archive)
+
+    stagedDir := filepath.Join(updateDir, updateStagedDirName)
+    tmpDir := stagedDir + ".tmp"
+    os.RemoveAll(tmpDir)
+    if err := extractZip(archive, tmpDir); err != nil {
+        os.RemoveAll(tmpDir)
+        return err
+    }
+    if err := os.WriteFile(filepath.Join(tmpDir, updateCompleteMarker), []byte(manifest.Version+"\n"), 0o644); err != nil {
+        os.RemoveAll(tmpDir)
+        return err
+    }
+    if err := os.RemoveAll(stagedDir); err != nil {
+        return err
+    }
+    return os.Rename(tmpDir, stagedDir)
+}
+
+// applyStagedUpdate копирует подготовленное обновление поверх каталога
+// установки. Заменяемые файлы сначала сохраняются в update\backup; при
+// любой ошибке они возвращаются на место, поэтому установка остаётся
+// в исходном состоянии.
+func applyStagedUpdate(baseDir string) {
+    stagedDir := filepath.Join(baseDir, updateDirName, updateStagedDirName)
+    marker, err := os.ReadFile(filepath.Join(stagedDir, updateCompleteMarker))
+    if err != nil {
+        return
+    }
+    newVersion := strings.TrimSpace(string(marker))
+    backupDir := filepath.Join(baseDir, updateDirName, updateBackupDirName)
+    os.RemoveAll(backupDir)
+
+    exePath, _ := os.Executable()
+    updatedExe := false
+    var applied []string
+    err = filepath.Walk(stagedDir, func(path string, info os.FileInfo, err error) error {
+        if err != nil || info.IsDir() {
+            return err
+        }
+        rel, err := filepath.Rel(stagedDir, path)
+        if err != nil || rel == updateCompleteMarker {
+            return err
+        }
+        target := filepath.Join(baseDir, rel)
+        if err := backupFile(target, filepath.Join(backupDir, rel), target == exePath); err != nil {
+            return err
+        }
+        applied = append(applied, rel)
+        updatedExe = updatedExe || target == exePath
+        return copyFile(path, target, info.Mode())
+    })
+    if err != nil {
+        logger.Printf("Не удалось установить обновление %s, восстанавливаю прежние файлы: %v", newVersion, err)
+        for _, rel := range applied {
+            restoreFile(filepath.Join(backupDir, rel), filepath.Join(baseDir, rel))
+        }
+        os.RemoveAll(stagedDir)
+        return
+    }
+    os.RemoveAll(stagedDir)
+    if err := os.WriteFile(filepath.Join(baseDir, updateDirName, updateInstalledName), []byte(newVersion+"\n"), 0o644); err != nil {
+        logger.Printf("Не удалось записать версию установленного обновления: %v", err)
+    }
+    logger.Event("update_applied").Printf("Установлено обновление %s. Прежние файлы сохранены в %s", newVersion, backupDir)
+    if updatedExe {
+        logger.Printf("Исполняемый файл лаунчера обновлён, новая версия начнёт работать со следующего запуска")
+    }
+}
+
+// backupFile переносит target в backup. Работающий exe нельзя перезаписать
+// в Windows, но можно переименовать, поэтому файлы переносятся, а не
+// копируются.
+func backupFile(target, backup string, running bool) error {
+    if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+    if err := os.MkdirAll(filepath.Dir(backup), 0o755); err != nil {
+        return err
+    }
+    if err := os.Rename(target, backup); err != nil {
+        if running {
+            return fmt.Errorf("не удалось переименовать работающий лаунчер: %w", err)
+        }
+        return err
+    }
+    return nil
+}
+
+func restoreFile(backup, target string) {
+    os.Remove(target)
+    if _, err := os.Stat(backup); err == nil {
+        os.Rename(backup, target)
+    }
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+    if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+        return err
+    }
+    in, err := os.Open(src)
+    if err != nil {
+        return err
+    }
+    defer in.Close()
+    out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(out, in); err != nil {
+        out.Close()
+        return err
+    }
+    return out.Close()
+}
+
+// compareVersions сравнивает версии вида 1.2.3 по числовым компонентам.
+// Нечисловой компонент сравнивается как строка. Предварительная версия
+// (1.3.0-rc1) старше предыдущих, но младше самого выпуска 1.3.0.
+func compareVersions(a, b string) int {
+    a, preA, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+    b, preB, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+    if c := compareVersionParts(a, b); c != 0 {
+        return c
+    }
+    switch {
+    case preA == preB:
+        return 0
+    case preA == "":
+        return 1
+    case preB == "":
+        return -1
+    }
+    return compareVersionParts(preA, preB)
+}
+
+func compareVersionParts(a, b string) int {
+    pa := strings.Split(a, ".")
+    pb := strings.Split(b, ".")
+    for i := 0; i < len(pa) || i < len(pb); i++ {
+        var x, y string
+        if i < len(pa) {
+            x = pa[i]
+        }
+        if i < len(pb) {
+            y = pb[i]
+        }
+        nx, errX := strconv.Atoi(x)
+        ny, errY := strconv.Atoi(y)
+        if x == "" {
+            nx, errX = 0, nil
+        }
+        if y == "" {
+            ny, errY = 0, nil
+        }
+        switch {
+        case errX == nil && errY == nil:
+            if nx != ny {
+                if nx < ny {
+                    return -1
+                }
+                return 1
+            }
+        case x != y:
+            if x < y {
+                return -1
+            }
+            return 1
+        }
+    }
+    return 0
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "crypto/ed25519"
+    "crypto/rand"
+    "encoding/base64"
+    "testing"
+)
+
+func TestUpdateManifestVerify(t *testing.T) {
+    pub, priv, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    signed := updateManifest{Version: "1.3.0", URL: "https://updates.example/egais-bot-1.3.0.zip", SHA256: "ab12"}
+    signed.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, signed.signedData()))
+
+    tests := []struct {
+        name    string
+        pub     ed25519.PublicKey
+        edit    func(m *updateManifest)
+        wantErr bool
+    }{
+        {"подпись верна", pub, func(*updateManifest) {}, false},
+        {"подменён sha256", pub, func(m *updateManifest) { m.SHA256 = "cd34" }, true},
+        {"подменён url", pub, func(m *updateManifest) { m.URL = "https://evil.example/x.zip" }, true},
+        {"подменена версия", pub, func(m *updateManifest) { m.Version = "9.0.0" }, true},
+        {"нет подписи", pub, func(m *updateManifest) { m.Signature = "" }, true},
+        {"подпись не base64", pub, func(m *updateManifest) { m.Signature = "!!!" }, true},
+        {"чужой ключ", otherPub, func(*updateManifest) {}, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            m := signed
+            tt.edit(&m)
+            if err := m.verify(tt.pub); (err != nil) != tt.wantErr {
+                t.Errorf("verify() = %v, ошибка ожидалась: %v", err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestUpdateVersionPattern(t *testing.T) {
+    tests := []struct {
+        version string
+        want    bool
+    }{
+        {"1.3.0", true},
+        {"v2.0", true},
+        {"1.3.0-rc1", true},
+        {"1.3.0-beta.2", true},
+        {"", false},
+        {"../../x", false},
+        {`1.0\..\..\x`, false},
+        {"1.0/x", false},
+        {"1..0", false},
+        {"1.0-..", false},
+        {"1.0 ", false},
+    }
+    for _, tt := range tests {
+        if got := updateVersionPattern.MatchString(tt.version); got != tt.want {
+            t.Errorf("updateVersionPattern(%q) = %v, ожидалось %v", tt.version, got, tt.want)
+        }
+    }
+}
+
+func TestCompareVersions(t *testing.T) {
+    tests := []struct {
+        a, b string
+        want int
+    }{
+        {"1.2.3", "1.2.3", 0},
+        {"v1.2.3", "1.2.3", 0},
+        {"1.2", "1.2.0", 0},
+        {"1.10.0", "1.9.0", 1},
+        {"1.2.3", "1.2.10", -1},
+        {"2.0", "1.99.99", 1},
+        {"1.3.0-rc1", "1.3.0", -1},
+        {"1.3.0", "1.3.0-rc1", 1},
+        {"1.3.0-rc1", "1.2.9", 1},
+        {"1.3.0-rc1", "1.3.0-rc2", -1},
+        {"1.3.0-beta.2", "1.3.0-beta.10", -1},
+        {"1.3.0-rc1", "1.3.0-rc1", 0},
+    }
+    for _, tt := range tests {
+        if got := compareVersions(tt.a, tt.b); got != tt.want {
+            t.Errorf("compareVersions(%q, %q) = %d, ожидалось %d", tt.a, tt.b, got, tt.want)
+        }
+    }
+}
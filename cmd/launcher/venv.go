@@ -0,0 +1,44 @@
+package main
+
+import (
+    "path/filepath"
+    "runtime"
+)
+
+const venvDirName = ".venv"
+
+func venvBinDir(venvDir string) string {
+    if runtime.GOOS == "windows" {
+        return filepath.Join(venvDir, "Scripts")
+    }
+    return filepath.Join(venvDir, "bin")
+}
+
+func venvCandidates(venvDir string) []string {
+    bin := venvBinDir(venvDir)
+    if runtime.GOOS == "windows" {
+        return []string{
+            filepath.Join(bin, "pythonw.exe"),
+            filepath.Join(bin, "python.exe"),
+        }
+    }
+    return []string{
+        filepath.Join(bin, "python3"),
+        filepath.Join(bin, "python"),
+    }
+}
+
+// findVenvPython возвращает интерпретатор из .venv в каталоге установки
+// или пустую строку, если окружения нет.
+func findVenvPython(baseDir string) (venvDir, pythonExe string) {
+    venvDir = filepath.Join(baseDir, venvDirName)
+    if pythonExe = findPython(venvCandidates(venvDir)); pythonExe == "" {
+        return "", ""
+    }
+    return venvDir, pythonExe
+}
+
+func activateVenv(env []string, venvDir string) []string {
+    env = setEnv(env, "VIRTUAL_ENV", venvDir)
+    return prependPath(env, venvBinDir(venvDir))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce — сколько после последнего изменения ждать перед
+// перезапуском: редактор и git обычно записывают несколько файлов подряд.
+const watchDebounce = time.Second
+
+// watchSkipDirs — каталоги, изменения в которых не означают правку бота.
+var watchSkipDirs = []string{"__pycache__", "site-packages", logDirName, updateDirName, embeddedPythonDir}
+
+// startSourceWatcher следит за .py-файлами в dir и его подкаталогах
+// (--watch) и через watchDebounce после последнего изменения просит
+// супервизор корректно перезапустить бота. Перед перезапуском каждый
+// изменённый файл проходит check: пустой или синтаксически неверный файл,
+// например записанный не до конца, перезапуска не вызывает, поэтому бот
+// не уходит в цикл падений, пока файл правят. Возвращаемая функция
+// останавливает наблюдение.
+func startSourceWatcher(dir string, check func(path string) error, ctl *controller) (func(), error) {
+    w, err := fsnotify.NewWatcher()
+    if err != nil {
+        return nil, err
+    }
+    if err := watchTree(w, dir); err != nil {
+        w.Close()
+        return nil, err
+    }
+
+    done := make(chan struct{})
+    go func() {
+        pending := make(map[string]bool)
+        var fire <-chan time.Time
+        for {
+            select {
+            case <-done:
+                return
+            case ev, ok := <-w.Events:
+                if !ok {
+                    return
+                }
+                if ev.Has(fsnotify.Create) {
+                    if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+                        if !skipWatchDir(info.Name()) {
+                            watchTree(w, ev.Name)
+                        }
+                        continue
+                    }
+                }
+                if ev.Op == fsnotify.Chmod || !strings.EqualFold(filepath.Ext(ev.Name), ".py") {
+                    continue
+                }
+                pending[ev.Name] = true
+                fire = time.After(watchDebounce)
+            case err, ok := <-w.Errors:
+                if !ok {
+                    return
+                }
+                logger.Printf("Предупреждение: наблюдение за %s: %v", dir, err)
+            case <-fire:
+                fire = nil
+                restartForChanges(dir, pending, check, ctl)
+                clear(pending)
+            }
+        }
+    }()
+    return func() {
+        close(done)
+        w.Close()
+    }, nil
+}
+
+func restartForChanges(dir string, pending map[string]bool, check func(string) error, ctl *controller) {
+    var changed []string
+    for path := range pending {
+        rel, err := filepath.Rel(dir, path)
+        if err != nil {
+            rel = path
+        }
+        info, err := os.Stat(path)
+        switch {
+        case err != nil:
+            // Удалённый или переименованный файл тоже меняет бота.
+        case info.Size() == 0:
+            logger.Event("source_rejected").Printf("Файл %s пуст (возможно, ещё записывается), бот не перезапущен", rel)
+            return
+        default:
+            if err := check(path); err != nil {
+                logger.Event("source_rejected").Printf("Изменения не применены, бот не перезапущен: %v. Исправьте файл и сохраните его снова", err)
+                return
+            }
+        }
+        changed = append(changed, rel)
+    }
+    sort.Strings(changed)
+    list := strings.Join(changed, ", ")
+    logger.Event("source_changed").Printf("Изменены файлы бота: %s, бот будет перезапущен", list)
+    ctl.requestRestart("изменены файлы " + list)
+}
+
+// watchTree добавляет dir и все его подкаталоги, кроме служебных:
+// fsnotify не следит за вложенными каталогами сам.
+func watchTree(w *fsnotify.Watcher, dir string) error {
+    return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            if path == dir {
+                return err
+            }
+            return nil
+        }
+        if !d.IsDir() {
+            return nil
+        }
+        if path != dir && skipWatchDir(d.Name()) {
+            return filepath.SkipDir
+        }
+        return w.Add(path)
+    })
+}
+
+func skipWatchDir(name string) bool {
+    if strings.HasPrefix(name, ".") {
+        return true
+    }
+    for _, skip := range watchSkipDirs {
+        if strings.EqualFold(name, skip) {
+            return true
+        }
+    }
+    return false
+}
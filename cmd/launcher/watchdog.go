@@ -0,0 +1,78 @@
+package main
+
+import (
+    "fmt"
+    "time"
+)
+
+const (
+    defaultWatchdogInterval = 30 * time.Second
+    defaultWatchdogSustain  = 5 * time.Minute
+)
+
+// watchdog следит за памятью (RSS) и загрузкой процессора процессом бота.
+// Если порог превышен дольше sustain, бот корректно перезапускается через
+// супервизор. Нулевой порог не проверяется.
+type watchdog struct {
+    maxMemory uint64
+    maxCPU    float64
+    interval  time.Duration
+    sustain   time.Duration
+    ctl       *controller
+}
+
+// run опрашивает процесс бота, пока не закрыт stop.
+func (w *watchdog) run(stop <-chan struct{}) {
+    ticker := time.NewTicker(w.interval)
+    defer ticker.Stop()
+
+    var pid int
+    var lastCPU time.Duration
+    var lastSample, overSince time.Time
+    for {
+        select {
+        case <-stop:
+            return
+        case <-ticker.C:
+        }
+        snap := w.ctl.status.snapshot()
+        if snap.State != stateRunning || snap.PID == 0 {
+            pid = 0
+            continue
+        }
+        rss, cpu, err := processUsage(snap.PID)
+        if err != nil {
+            logger.Debugf("Не удалось получить потребление ресурсов PID %d: %v", snap.PID, err)
+            continue
+        }
+        now := time.Now()
+        if snap.PID != pid {
+            pid, lastCPU, lastSample, overSince = snap.PID, cpu, now, time.Time{}
+            continue
+        }
+        cpuPercent := float64(cpu-lastCPU) / float64(now.Sub(lastSample)) * 100
+        lastCPU, lastSample = cpu, now
+        logger.Debugf("Ресурсы бота: память %d МБ, процессор %.0f%%", rss>>20, cpuPercent)
+
+        var over string
+        switch {
+        case w.maxMemory > 0 && rss > w.maxMemory:
+            over = fmt.Sprintf("память %d МБ при пороге %d МБ", rss>>20, w.maxMemory>>20)
+        case w.maxCPU > 0 && cpuPercent > w.maxCPU:
+            over = fmt.Sprintf("процессор %.0f%% при пороге %.0f%%", cpuPercent, w.maxCPU)
+        }
+        if over == "" {
+            overSince = time.Time{}
+            continue
+        }
+        if overSince.IsZero() {
+            overSince = now
+            logger.Printf("Бот превысил лимит ресурсов: %s", over)
+        }
+        if now.Sub(overSince) >= w.sustain {
+            logger.Event("watchdog_restart").Printf("Лимит ресурсов превышен дольше %s (%s), бот будет перезапущен", w.sustain, over)
+            w.ctl.requestRestart("превышен лимит ресурсов: " + over)
+            overSince = time.Time{}
+        }
+    }
+}
@@ -1,3 +1,11 @@
 module datamatrix-launcher
 
 go 1.24.3
+
+require (
+	fyne.io/systray v1.11.0
+	github.com/fsnotify/fsnotify v1.10.1
+	golang.org/x/sys v0.38.0
+)
+
+require github.com/godbus/dbus/v5 v5.1.0 // indirect